@@ -7,6 +7,7 @@ require (
 	github.com/hako/durafmt v0.0.0-20200710122514-c0fb7b4da026
 	github.com/mmcdole/gofeed v1.1.0
 	github.com/pkg/errors v0.9.1
+	github.com/robfig/cron/v3 v3.0.1
 	github.com/slack-go/slack v0.7.4
 	github.com/spf13/viper v1.7.1
 	github.com/stretchr/testify v1.4.0
@@ -17,19 +17,32 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"runtime"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/Dentrax/remind-us/pkg/alerters"
+	"github.com/Dentrax/remind-us/pkg/alerters/discord"
+	"github.com/Dentrax/remind-us/pkg/alerters/email"
+	"github.com/Dentrax/remind-us/pkg/alerters/mattermost"
+	"github.com/Dentrax/remind-us/pkg/alerters/rocketchat"
 	"github.com/Dentrax/remind-us/pkg/alerters/slack"
+	"github.com/Dentrax/remind-us/pkg/alerters/teams"
+	"github.com/Dentrax/remind-us/pkg/alerters/webhook"
 	"github.com/Dentrax/remind-us/pkg/config"
 	"github.com/Dentrax/remind-us/pkg/integrations"
 	"github.com/Dentrax/remind-us/pkg/integrations/gitlab"
 	rss "github.com/Dentrax/remind-us/pkg/integrations/rss"
+	"github.com/Dentrax/remind-us/pkg/reminder"
+	"github.com/Dentrax/remind-us/pkg/scheduler"
+	"github.com/Dentrax/remind-us/pkg/state"
 	"github.com/pkg/errors"
 )
 
@@ -46,6 +59,7 @@ func main() {
 
 	flag.StringVar(&configPath, "config-file", "./config.yaml", "Configuration file path")
 	v := flag.Bool("v", false, "Prints current version")
+	daemon := flag.Bool("daemon", false, "Runs as a long-running scheduler using the schedules in config")
 	flag.Parse()
 
 	if *v {
@@ -66,7 +80,14 @@ func main() {
 		log.Fatal(err)
 	}
 
-	err = Run(c)
+	if *daemon {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		err = Daemon(ctx, c)
+	} else {
+		err = Run(c)
+	}
 
 	if err != nil {
 		log.Fatal(err)
@@ -74,56 +95,188 @@ func main() {
 }
 
 func Run(config *config.Config) error {
+	store, err := state.New(config.State)
+	if err != nil {
+		return errors.Wrap(err, "unable to create state store")
+	}
+
 	for _, i := range []integrations.IIntegration{
 		&gitlab.GitLab{},
 		&rss.RSS{
 			InitialTime: InitialTime,
+			State:       store,
 		},
 	} {
 		if !i.Enabled(config.Integrations) {
 			continue
 		}
 
-		if err := i.Validate(config.Integrations); err != nil {
-			return errors.Wrapf(err, "Could not validate '%s' config", i.Name())
+		if err := run(i, config.Integrations, config.Alerts); err != nil {
+			return err
 		}
+	}
 
-		err := i.Load(config.Integrations)
-		if err != nil {
-			return errors.Wrapf(err, "unable to load integration: '%s'", i.Name())
-		}
+	return nil
+}
+
+// Daemon schedules each enabled integration on its own schedule
+// and blocks until the ctx is done. RSS sources are grouped by their
+// schedules, a source without a schedule uses the RSS one.
+func Daemon(ctx context.Context, config *config.Config) error {
+	store, err := state.New(config.State)
+	if err != nil {
+		return errors.Wrap(err, "unable to create state store")
+	}
 
-		message, err := i.GenerateSlackMessage(integrations.GenerateMessageOptions{})
+	s := scheduler.New()
+
+	if g := (&gitlab.GitLab{}); g.Enabled(config.Integrations) {
+		err := s.Add(g.Name(), config.Integrations.GitLab.Schedule, func() error {
+			return run(&gitlab.GitLab{}, config.Integrations, config.Alerts)
+		})
 		if err != nil {
-			return errors.Wrapf(err, "unable to generate slack message for integration: '%s'", i.Name())
+			return err
 		}
+	}
 
-		if len(message.Attachments) == 0 {
-			log.Printf("0 Attachments found for %s, no need to alert", i.Name())
-			continue
+	if r := (&rss.RSS{}); r.Enabled(config.Integrations) {
+		// the groups are validated on each run, the duplicate
+		// sources across the groups only by validating them all
+		if err := r.Validate(config.Integrations); err != nil {
+			return errors.Wrapf(err, "Could not validate '%s' config", r.Name())
 		}
 
-		for _, a := range []alerters.IAlerter{
-			&slack.Slack{},
-		} {
-			if !a.Enabled(config.Alerts) {
-				continue
-			}
+		for _, c := range groupRSSSourcesBySchedule(config.Integrations.RSS) {
+			c := c
 
-			err := a.Load(config.Alerts)
+			err := s.Add(fmt.Sprintf("%s (%s)", r.Name(), c.Schedule), c.Schedule, func() error {
+				return run(newRSS(store), config.Integrations.WithRSS(c), config.Alerts)
+			})
 			if err != nil {
-				return errors.Wrapf(err, "unable to load integration: '%s'", i.Name())
+				return err
 			}
+		}
+	}
 
-			err = a.Alert(message)
+	return s.Run(ctx)
+}
 
-			if err != nil {
-				return errors.Wrapf(err, "unable to alert message for alerter: '%s', message: '%+v'", a.Name(), message)
-			}
+// newRSS returns the RSS integration of a scheduled run, the
+// runs of all the schedules share the same state store, so an
+// item is alerted once even if it is matched by multiple groups.
+func newRSS(store state.IStore) *rss.RSS {
+	return &rss.RSS{
+		InitialTime: time.Now(),
+		State:       store,
+	}
+}
+
+// groupRSSSourcesBySchedule splits the given config into
+// configs which each contains the sources sharing the same schedule.
+func groupRSSSourcesBySchedule(c *config.RSSIntegrationConfig) []*config.RSSIntegrationConfig {
+	var result []*config.RSSIntegrationConfig
+
+	bySchedule := make(map[string]*config.RSSIntegrationConfig)
+
+	for _, source := range c.Sources {
+		schedule := source.Schedule
+		if schedule == "" {
+			schedule = c.Schedule
+		}
+
+		g, ok := bySchedule[schedule]
+		if !ok {
+			// the other settings are shared by all the groups
+			copied := *c
+			copied.Schedule = schedule
+			copied.Sources = nil
+
+			g = &copied
+			bySchedule[schedule] = g
+			result = append(result, g)
+		}
+
+		g.Sources = append(g.Sources, source)
+	}
 
-			log.Printf("%s alert success for channel: %s\n", a.Name(), message.Channel)
+	return result
+}
+
+func run(i integrations.IIntegration, config config.Integrations, alerts config.AlertConfig) error {
+	if err := i.Validate(config); err != nil {
+		return errors.Wrapf(err, "Could not validate '%s' config", i.Name())
+	}
+
+	err := i.Load(config)
+	if err != nil {
+		return errors.Wrapf(err, "unable to load integration: '%s'", i.Name())
+	}
+
+	// the reminder is generated once, the pending items of
+	// the integration are the ones of the reminder alerted
+	r, err := i.GenerateReminder(integrations.GenerateMessageOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "unable to generate reminder for integration: '%s'", i.Name())
+	}
+
+	return alert(i, r, alerts, []alerters.IAlerter{
+		&slack.Slack{},
+		&teams.Teams{},
+		&discord.Discord{},
+		&email.Email{},
+		&mattermost.Mattermost{},
+		&rocketchat.RocketChat{},
+		&webhook.Webhook{},
+	})
+}
+
+// alert alerts the reminder by each enabled alerter, a failing alerter
+// does not stop the others. The integration is committed if any of them
+// has alerted, not to alert the same items again by the ones succeeded,
+// then the failures are returned.
+func alert(i integrations.IIntegration, r *reminder.Reminder, alerts config.AlertConfig, as []alerters.IAlerter) error {
+	if len(r.Sections) == 0 {
+		log.Printf("0 Sections found for %s, no need to alert\n", i.Name())
+
+		return nil
+	}
+
+	alerted := 0
+
+	var failures []string
+
+	for _, a := range as {
+		if !a.Enabled(alerts) {
+			continue
+		}
+
+		if err := a.Load(alerts); err != nil {
+			failures = append(failures, errors.Wrapf(err, "unable to load alerter: '%s'", a.Name()).Error())
+
+			continue
+		}
+
+		if err := a.Alert(r); err != nil {
+			failures = append(failures, errors.Wrapf(err, "unable to alert reminder for alerter: '%s', reminder: '%+v'", a.Name(), r).Error())
+
+			continue
+		}
+
+		log.Printf("%s alert success for %s\n", a.Name(), i.Name())
+
+		alerted++
+	}
+
+	// nothing is marked as alerted unless an alerter has alerted
+	if c, ok := i.(integrations.ICommitter); ok && alerted > 0 {
+		if err := c.Commit(); err != nil {
+			failures = append(failures, errors.Wrapf(err, "unable to commit integration: '%s'", i.Name()).Error())
 		}
 	}
 
+	if len(failures) > 0 {
+		return errors.New(strings.Join(failures, "; "))
+	}
+
 	return nil
 }
@@ -0,0 +1,237 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Dentrax/remind-us/pkg/alerters"
+	"github.com/Dentrax/remind-us/pkg/config"
+	"github.com/Dentrax/remind-us/pkg/integrations"
+	"github.com/Dentrax/remind-us/pkg/reminder"
+	"github.com/Dentrax/remind-us/pkg/state"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeIntegration struct {
+	commits int
+}
+
+func (f *fakeIntegration) Name() string                       { return "fake" }
+func (f *fakeIntegration) Enabled(config.Integrations) bool   { return true }
+func (f *fakeIntegration) Validate(config.Integrations) error { return nil }
+func (f *fakeIntegration) Load(config.Integrations) error     { return nil }
+func (f *fakeIntegration) Commit() error                      { f.commits++; return nil }
+func (f *fakeIntegration) GenerateReminder(integrations.GenerateMessageOptions) (*reminder.Reminder, error) {
+	return nil, nil
+}
+
+type fakeAlerter struct {
+	name    string
+	enabled bool
+	err     error
+	alerted int
+}
+
+func (f *fakeAlerter) Name() string                    { return f.name }
+func (f *fakeAlerter) Enabled(config.AlertConfig) bool { return f.enabled }
+func (f *fakeAlerter) Load(config.AlertConfig) error   { return nil }
+func (f *fakeAlerter) Alert(*reminder.Reminder) error {
+	if f.err != nil {
+		return f.err
+	}
+
+	f.alerted++
+
+	return nil
+}
+
+func TestAlert(t *testing.T) {
+	t.Parallel()
+
+	r := &reminder.Reminder{Sections: []reminder.Section{{Title: "foo"}}}
+
+	tests := []struct {
+		name        string
+		reminder    *reminder.Reminder
+		alerters    []*fakeAlerter
+		wantCommits int
+		wantAlerted []int
+		wantErr     bool
+	}{
+		{
+			name:        "no alerter enabled",
+			reminder:    r,
+			alerters:    []*fakeAlerter{{name: "a"}},
+			wantCommits: 0,
+			wantAlerted: []int{0},
+		},
+		{
+			name:        "no sections",
+			reminder:    &reminder.Reminder{},
+			alerters:    []*fakeAlerter{{name: "a", enabled: true}},
+			wantCommits: 0,
+			wantAlerted: []int{0},
+		},
+		{
+			name:        "all succeeded",
+			reminder:    r,
+			alerters:    []*fakeAlerter{{name: "a", enabled: true}, {name: "b", enabled: true}},
+			wantCommits: 1,
+			wantAlerted: []int{1, 1},
+		},
+		{
+			name:        "first failed",
+			reminder:    r,
+			alerters:    []*fakeAlerter{{name: "a", enabled: true, err: errors.New("foo")}, {name: "b", enabled: true}},
+			wantCommits: 1,
+			wantAlerted: []int{0, 1},
+			wantErr:     true,
+		},
+		{
+			name:        "all failed",
+			reminder:    r,
+			alerters:    []*fakeAlerter{{name: "a", enabled: true, err: errors.New("foo")}},
+			wantCommits: 0,
+			wantAlerted: []int{0},
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			i := &fakeIntegration{}
+
+			as := make([]alerters.IAlerter, 0, len(tt.alerters))
+			for _, a := range tt.alerters {
+				as = append(as, a)
+			}
+
+			err := alert(i, tt.reminder, config.AlertConfig{}, as)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.wantCommits, i.commits)
+
+			for n, a := range tt.alerters {
+				assert.Equal(t, tt.wantAlerted[n], a.alerted, a.name)
+			}
+		})
+	}
+}
+
+func TestGroupRSSSourcesBySchedule(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		config *config.RSSIntegrationConfig
+		want   map[string][]string
+	}{
+		{
+			name: "it should fall back to the RSS schedule",
+			config: &config.RSSIntegrationConfig{
+				Schedule: "@every 1h",
+				Sources:  []config.RSSSourceConfig{{URL: "a"}, {URL: "b"}},
+			},
+			want: map[string][]string{"@every 1h": {"a", "b"}},
+		},
+		{
+			name: "it should group the sources by their schedules",
+			config: &config.RSSIntegrationConfig{
+				Schedule: "@every 1h",
+				MaxItems: 10,
+				Sources: []config.RSSSourceConfig{
+					{URL: "a", Schedule: "15m"},
+					{URL: "b"},
+					{URL: "c", Schedule: "15m"},
+					{URL: "d", Schedule: "0 9 * * *"},
+				},
+			},
+			want: map[string][]string{"15m": {"a", "c"}, "@every 1h": {"b"}, "0 9 * * *": {"d"}},
+		},
+		{
+			name: "it should keep the rules of the same URL in their own groups",
+			config: &config.RSSIntegrationConfig{
+				Schedule: "@every 1h",
+				Sources: []config.RSSSourceConfig{
+					{URL: "a", Name: "cve", Schedule: "15m"},
+					{URL: "a", Name: "release"},
+				},
+			},
+			want: map[string][]string{"15m": {"a#cve"}, "@every 1h": {"a#release"}},
+		},
+		{
+			name:   "no sources",
+			config: &config.RSSIntegrationConfig{Schedule: "@every 1h"},
+			want:   map[string][]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := make(map[string][]string)
+
+			for _, g := range groupRSSSourcesBySchedule(tt.config) {
+				_, ok := got[g.Schedule]
+				assert.False(t, ok, "it should have a group per schedule")
+
+				// the other settings are shared by all the groups
+				assert.Equal(t, tt.config.MaxItems, g.MaxItems)
+
+				keys := []string{}
+
+				for _, s := range g.Sources {
+					key := s.URL
+					if s.Name != "" {
+						key += "#" + s.Name
+					}
+
+					keys = append(keys, key)
+				}
+
+				got[g.Schedule] = keys
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewRSS(t *testing.T) {
+	t.Parallel()
+
+	store := state.NewMemoryStore(time.Hour)
+
+	a, b := newRSS(store), newRSS(store)
+
+	assert.False(t, a == b, "each run should have its own integration")
+	assert.Same(t, store, a.State, "the runs should share the same store")
+	assert.Same(t, store, b.State, "the runs should share the same store")
+}
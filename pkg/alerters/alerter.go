@@ -16,11 +16,14 @@ limitations under the License.
 
 package alerters
 
-import "github.com/Dentrax/remind-us/pkg/config"
+import (
+	"github.com/Dentrax/remind-us/pkg/config"
+	"github.com/Dentrax/remind-us/pkg/reminder"
+)
 
 type IAlerter interface {
 	Name() string
 	Enabled(config.AlertConfig) bool
 	Load(alertConfig config.AlertConfig) error
-	Alert(message interface{}) error
+	Alert(reminder *reminder.Reminder) error
 }
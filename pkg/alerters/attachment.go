@@ -0,0 +1,138 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alerters
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Dentrax/remind-us/pkg/reminder"
+)
+
+// Attachment is a Slack-compatible attachment of a section, the
+// alerters posting Slack-like payloads map it onto their own.
+type Attachment struct {
+	// Fallback is the plaintext of the section, shown in the notifications.
+	Fallback string
+	Severity reminder.Severity
+	// Pretext mentions the users of the section.
+	Pretext   string
+	Title     string
+	TitleLink string
+	Icon      string
+	// Text is the summary and the groups of the section, as they
+	// are rendered in Slack, i.e. starting by a line break.
+	Text string
+	// Fields are the items of the section, then the overflow, if any.
+	Fields     []string
+	Footer     string
+	FooterIcon string
+	// Timestamp is zero if omitted.
+	Timestamp time.Time
+}
+
+// Attachments renders each section into an attachment, where the
+// items are the fields and the groups are rendered into the text.
+func (m Markup) Attachments(r *reminder.Reminder) []Attachment {
+	attachments := make([]Attachment, 0, len(r.Sections))
+
+	for _, s := range r.Sections {
+		var text strings.Builder
+
+		if len(s.Summary) > 0 {
+			text.WriteString(m.Text(s.Summary))
+			text.WriteString("\n")
+		}
+
+		for i, g := range s.Groups {
+			if i > 0 && !s.Groups[i-1].Break {
+				text.WriteString("\n")
+			}
+
+			text.WriteString("\n")
+			text.WriteString(m.heading(fmt.Sprintf("%s:", g.Title)))
+
+			for _, item := range g.Items {
+				text.WriteString("\n")
+				text.WriteString(m.Item(item))
+			}
+
+			if g.Break {
+				text.WriteString("\n")
+			}
+		}
+
+		var fields []string
+
+		for _, item := range s.Items {
+			fields = append(fields, m.Item(item))
+		}
+
+		if more := s.Overflow(); more != nil {
+			fields = append(fields, m.Text(more))
+		}
+
+		attachments = append(attachments, Attachment{
+			Fallback:   fallback(s),
+			Severity:   s.Severity,
+			Pretext:    m.Mentions(s.Mentions),
+			Title:      s.Title,
+			TitleLink:  s.Link,
+			Icon:       s.Icon,
+			Text:       text.String(),
+			Fields:     fields,
+			Footer:     s.Footer,
+			FooterIcon: s.FooterIcon,
+			Timestamp:  s.Timestamp,
+		})
+	}
+
+	return attachments
+}
+
+func (m Markup) heading(text string) string {
+	if m.Heading == nil {
+		return text
+	}
+
+	return m.Heading(text)
+}
+
+// fallback is shown in the notifications, i.e. "baz: There are 2 open MRs in baz."
+func fallback(s reminder.Section) string {
+	if len(s.Summary) == 0 {
+		return s.Title
+	}
+
+	return fmt.Sprintf("%s: %s", s.Title, s.Summary.String())
+}
+
+// Color returns the hex colors of Slack attachments by the severity,
+// for the alerters that look the same.
+func Color(s reminder.Severity) string {
+	switch s {
+	case reminder.SeverityWarning:
+		return "#DAA038"
+	case reminder.SeverityCritical:
+		return "#A30200"
+	case reminder.SeverityOK:
+		fallthrough
+	default:
+		return "#2EB886"
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alerters
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Dentrax/remind-us/pkg/reminder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkup_Attachments(t *testing.T) {
+	t.Parallel()
+
+	m := Markdown.WithMention(func(u reminder.User) string { return fmt.Sprintf("@%s", u.Username) })
+	m.Heading = m.Bold
+
+	author := &reminder.User{Username: "foo"}
+
+	got := m.Attachments(&reminder.Reminder{Sections: []reminder.Section{{
+		Title:    "baz",
+		Link:     "https://gitlab.com/foo/baz",
+		Summary:  reminder.Text{reminder.Plain("There are "), reminder.Link("2 open MRs", "https://gitlab.com/foo/baz/merge_requests")},
+		Severity: reminder.SeverityWarning,
+		Mentions: []reminder.User{{Username: "here", Group: true}},
+		Groups: []reminder.Group{
+			{Title: "1 MR is reviewed", Items: []reminder.Item{{Status: reminder.StatusFailed, Title: "a", Link: "https://a", Author: author}}, Break: true},
+			{Title: "1 MR is awaiting review", Items: []reminder.Item{{Status: reminder.StatusOK, Title: "b", Link: "https://b", Author: author, Reviewers: []reminder.User{*author, {Username: "bar"}}}}},
+		},
+		Items: []reminder.Item{{Title: "c", Link: "https://c"}},
+		More:  2,
+	}}})
+
+	assert.Equal(t, []Attachment{{
+		Fallback:  "baz: There are 2 open MRs",
+		Severity:  reminder.SeverityWarning,
+		Pretext:   "@here",
+		Title:     "baz",
+		TitleLink: "https://gitlab.com/foo/baz",
+		Text: "There are [2 open MRs](https://gitlab.com/foo/baz/merge_requests)\n" +
+			"\n**1 MR is reviewed:**\n✘ [a](https://a)  by @foo\n" +
+			"\n**1 MR is awaiting review:**\n✓ [b](https://b)  by @foo, reviewers: @bar",
+		Fields: []string{"• [c](https://c) ", "[…and 2 more](https://gitlab.com/foo/baz)"},
+	}}, got)
+}
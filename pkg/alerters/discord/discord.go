@@ -0,0 +1,230 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discord
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Dentrax/remind-us/pkg/alerters"
+	"github.com/Dentrax/remind-us/pkg/config"
+	"github.com/Dentrax/remind-us/pkg/reminder"
+	"github.com/pkg/errors"
+)
+
+var errAlert = errors.New("discord is not loaded")
+
+const (
+	defaultTimeout = 30 * time.Second
+
+	// maxRetries is the number of the attempts after
+	// the first one, if the webhook is rate limited.
+	maxRetries = 3
+
+	// maxRetryAfter caps the wait of a rate limited attempt.
+	maxRetryAfter = time.Minute
+)
+
+type Discord struct {
+	config *config.DiscordAlertConfig
+
+	// channels stores the webhooks of the channels.
+	//
+	// map: K: lower-cased Channel, V: Webhook URL
+	channels map[string]string
+
+	// users stores the Discord user IDs, and the role IDs of the groups.
+	//
+	// map: K: lower-cased Username or Email, V: Discord ID
+	users map[string]string
+
+	client *http.Client
+	loaded bool
+}
+
+func (d *Discord) Name() string {
+	return "Discord"
+}
+
+func (d *Discord) Enabled(config config.AlertConfig) bool {
+	if config.Discord == nil {
+		return false
+	}
+
+	if config.Discord.Enabled == "" {
+		return true
+	}
+
+	v, _ := strconv.ParseBool(config.Discord.Enabled)
+
+	return v
+}
+
+func (d *Discord) Load(config config.AlertConfig) error {
+	if _, err := url.ParseRequestURI(config.Discord.Webhook); err != nil {
+		return errors.Wrapf(err, "incorrect 'webhook' pattern: '%s'", config.Discord.Webhook)
+	}
+
+	channels := make(map[string]string, len(config.Discord.Channels))
+
+	for k, v := range config.Discord.Channels {
+		if _, err := url.ParseRequestURI(v); err != nil {
+			return errors.Wrapf(err, "incorrect webhook pattern of channel: '%s'", k)
+		}
+
+		channels[strings.ToLower(k)] = v
+	}
+
+	users := make(map[string]string, len(config.Discord.Users))
+
+	for k, v := range config.Discord.Users {
+		users[strings.ToLower(k)] = v
+	}
+
+	d.config = config.Discord
+	d.channels = channels
+	d.users = users
+	d.client = &http.Client{Timeout: defaultTimeout}
+	d.loaded = true
+
+	return nil
+}
+
+func (d *Discord) Alert(r *reminder.Reminder) error {
+	if !d.loaded {
+		return errAlert
+	}
+
+	// sections are posted to the webhooks of their channels, if any
+	for _, channel := range r.Channels() {
+		webhook, ok := d.channels[strings.ToLower(channel)]
+		if !ok {
+			webhook = d.config.Webhook
+		}
+
+		for _, m := range Render(r.ForChannel(channel), d.Mention) {
+			m.Username = d.config.Username
+			m.AvatarURL = d.config.Avatar
+
+			if err := d.post(webhook, m); err != nil {
+				return errors.Wrap(err, "unable to post webhook during alerting")
+			}
+		}
+	}
+
+	alerters.SkipDirect(r.Direct(), "direct messages are not supported by Discord")
+
+	return nil
+}
+
+// Mention returns the mention of the user by the mapping, i.e.
+// "<@80351110224678912>", or the name of the user if it is not
+// mapped. The groups are mentioned as roles, i.e. "<@&165511591545143296>",
+// where "here" and "everyone" are the special ones.
+func (d *Discord) Mention(u reminder.User) string {
+	id, ok := d.users[strings.ToLower(u.Username)]
+	if !ok && u.Email != "" {
+		id, ok = d.users[strings.ToLower(u.Email)]
+	}
+
+	if u.Group {
+		switch special := strings.ToLower(strings.TrimPrefix(u.Username, "@")); {
+		case special == "here" || special == "everyone":
+			return fmt.Sprintf("@%s", special)
+		case ok:
+			return fmt.Sprintf("<@&%s>", id)
+		default:
+			return fmt.Sprintf("@%s", u.Username)
+		}
+	}
+
+	if ok {
+		return fmt.Sprintf("<@%s>", id)
+	}
+
+	if u.Name != "" {
+		return u.Name
+	}
+
+	return u.Username
+}
+
+// post posts the message, the rate limited attempts are
+// retried after the wait told by Discord.
+func (d *Discord) post(webhook string, m Message) error {
+	body, err := json.Marshal(m)
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal message")
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := d.client.Post(webhook, "application/json", bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+
+		b, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < maxRetries {
+			wait := retryAfter(resp.Header, b)
+
+			log.Printf("discord webhook is rate limited, retrying in %s\n", wait)
+
+			time.Sleep(wait)
+
+			continue
+		}
+
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return errors.Errorf("unexpected status: '%s': %s", resp.Status, strings.TrimSpace(string(b)))
+		}
+
+		return nil
+	}
+}
+
+// retryAfter returns the wait of a rate limited response, by the
+// "retry_after" of the body in seconds, else by the Retry-After header.
+// See: https://discord.com/developers/docs/topics/rate-limits
+func retryAfter(header http.Header, body []byte) time.Duration {
+	var limited struct {
+		RetryAfter float64 `json:"retry_after"`
+	}
+
+	wait := time.Second
+
+	if err := json.Unmarshal(body, &limited); err == nil && limited.RetryAfter > 0 {
+		wait = time.Duration(limited.RetryAfter * float64(time.Second))
+	} else if s, err := strconv.ParseFloat(header.Get("Retry-After"), 64); err == nil && s > 0 {
+		wait = time.Duration(s * float64(time.Second))
+	}
+
+	if wait > maxRetryAfter {
+		wait = maxRetryAfter
+	}
+
+	return wait
+}
@@ -0,0 +1,240 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discord
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Dentrax/remind-us/pkg/config"
+	"github.com/Dentrax/remind-us/pkg/reminder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscord_Mention(t *testing.T) {
+	t.Parallel()
+
+	d := &Discord{
+		users: map[string]string{
+			"foo":             "80351110224678912",
+			"bar@example.com": "80351110224678913",
+			"s0team":          "165511591545143296",
+		},
+	}
+
+	tests := []struct {
+		name string
+		user reminder.User
+		want string
+	}{
+		{"by username", reminder.User{Username: "foo"}, "<@80351110224678912>"},
+		{"by email", reminder.User{Username: "bar", Email: "bar@example.com"}, "<@80351110224678913>"},
+		{"by name if not mapped", reminder.User{Username: "baz", Name: "Baz"}, "Baz"},
+		{"by username if not mapped", reminder.User{Username: "baz"}, "baz"},
+		{"the role", reminder.User{Username: "S0TEAM", Group: true}, "<@&165511591545143296>"},
+		{"here", reminder.User{Username: "here", Group: true}, "@here"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, d.Mention(tt.user))
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	ts := time.Date(2021, time.March, 24, 20, 0, 0, 0, time.UTC)
+
+	got := Render(&reminder.Reminder{
+		Sections: []reminder.Section{
+			{
+				Title:      "baz",
+				Link:       "https://gitlab.com/foo/baz",
+				Icon:       "https://gitlab.com/avatar.png",
+				Summary:    reminder.Text{reminder.Plain("There is "), reminder.Link("1 open MR", "https://gitlab.com/foo/baz/merge_requests?state=opened")},
+				Groups:     []reminder.Group{{Title: "1 MR is awaiting review", Items: []reminder.Item{{Title: "foo", Link: "https://gitlab.com/foo/baz/-/merge_requests/1"}}}},
+				Footer:     "foo",
+				FooterIcon: "https://gitlab.com/foo.png",
+				Severity:   reminder.SeverityWarning,
+				Timestamp:  ts,
+				Mentions:   []reminder.User{{Username: "here", Group: true}},
+			},
+			{
+				Title: "Hacker News",
+				Link:  "https://news.ycombinator.com",
+				Items: []reminder.Item{{Status: reminder.StatusOK, Title: "bar", Link: "https://news.ycombinator.com/item?id=1"}},
+				More:  14,
+			},
+		},
+	}, func(u reminder.User) string { return "@" + u.Username })
+
+	assert.Equal(t, []Message{
+		{
+			Content: "@here",
+			Embeds: []Embed{
+				{
+					Title:       "baz",
+					URL:         "https://gitlab.com/foo/baz",
+					Description: "There is [1 open MR](https://gitlab.com/foo/baz/merge_requests?state=opened)\n\n**1 MR is awaiting review:**\n• [foo](https://gitlab.com/foo/baz/-/merge_requests/1)",
+					Color:       0xDAA038,
+					Thumbnail:   &Image{URL: "https://gitlab.com/avatar.png"},
+					Footer:      &Footer{Text: "foo", IconURL: "https://gitlab.com/foo.png"},
+					Timestamp:   "2021-03-24T20:00:00Z",
+				},
+				{
+					Title: "Hacker News",
+					URL:   "https://news.ycombinator.com",
+					Color: 0x2EB886,
+					Fields: []Field{
+						{Name: emptyFieldName, Value: "✓ [bar](https://news.ycombinator.com/item?id=1) "},
+						{Name: emptyFieldName, Value: "[…and 14 more](https://news.ycombinator.com)"},
+					},
+				},
+			},
+		},
+	}, got)
+}
+
+func TestRender_Split(t *testing.T) {
+	t.Parallel()
+
+	items := make([]reminder.Item, 60)
+
+	for i := range items {
+		items[i] = reminder.Item{Title: strings.Repeat("x", 200), Link: fmt.Sprintf("https://example.com/%d", i)}
+	}
+
+	sections := make([]reminder.Section, 12)
+
+	for i := range sections {
+		sections[i] = reminder.Section{Title: fmt.Sprintf("section %d", i)}
+	}
+
+	sections[0].Items = items
+	sections[0].Footer = "footer"
+
+	got := Render(&reminder.Reminder{Sections: sections}, func(u reminder.User) string { return u.Username })
+
+	embeds := 0
+	fields := 0
+
+	for _, m := range got {
+		assert.LessOrEqual(t, len(m.Embeds), maxEmbeds)
+
+		size := 0
+
+		for _, e := range m.Embeds {
+			assert.LessOrEqual(t, len(e.Fields), maxFields)
+
+			size += e.size()
+			fields += len(e.Fields)
+		}
+
+		assert.LessOrEqual(t, size, maxMessageChars)
+
+		embeds += len(m.Embeds)
+	}
+
+	assert.Greater(t, len(got), 1)
+	assert.Equal(t, 60, fields)
+
+	// the footer is on the last embed of the section
+	first := got[0].Embeds
+	assert.Equal(t, "section 0", first[0].Title)
+	assert.Nil(t, first[0].Footer)
+	assert.Greater(t, embeds, len(sections))
+}
+
+func TestDiscord_Alert(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu    sync.Mutex
+		posts []string
+		calls int
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		calls++
+
+		// the first attempt is rate limited
+		if calls == 1 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"message": "You are being rate limited.", "retry_after": 0.01, "global": false}`))
+
+			return
+		}
+
+		var m Message
+
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&m))
+		assert.Equal(t, "remind-us", m.Username)
+
+		posts = append(posts, r.URL.Path+":"+m.Embeds[0].Title)
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	t.Cleanup(ts.Close)
+
+	d := &Discord{}
+
+	assert.True(t, d.Enabled(config.AlertConfig{Discord: &config.DiscordAlertConfig{}}))
+	assert.False(t, d.Enabled(config.AlertConfig{Discord: &config.DiscordAlertConfig{Enabled: "false"}}))
+
+	err := d.Load(config.AlertConfig{Discord: &config.DiscordAlertConfig{
+		Webhook:  ts.URL + "/general",
+		Username: "remind-us",
+		Channels: map[string]string{"#Escalations": ts.URL + "/escalations"},
+	}})
+	assert.NoError(t, err)
+
+	err = d.Alert(&reminder.Reminder{
+		Sections: []reminder.Section{
+			{Title: "foo"},
+			{Title: "bar", Channel: "#escalations"},
+			{Title: "digest of foo", Recipient: &reminder.User{Username: "foo"}},
+		},
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"/general:foo", "/escalations:bar"}, posts)
+	assert.Equal(t, 3, calls)
+}
+
+func TestRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 1500*time.Millisecond, retryAfter(http.Header{}, []byte(`{"retry_after": 1.5}`)))
+	assert.Equal(t, 2*time.Second, retryAfter(http.Header{"Retry-After": []string{"2"}}, nil))
+	assert.Equal(t, time.Second, retryAfter(http.Header{}, nil))
+	assert.Equal(t, maxRetryAfter, retryAfter(http.Header{}, []byte(`{"retry_after": 3600}`)))
+}
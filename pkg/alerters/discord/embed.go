@@ -0,0 +1,271 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discord
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Dentrax/remind-us/pkg/alerters"
+	"github.com/Dentrax/remind-us/pkg/reminder"
+)
+
+// The limits of a message, see: https://discord.com/developers/docs/resources/channel#embed-limits
+const (
+	maxEmbeds           = 10
+	maxMessageChars     = 6000
+	maxContentChars     = 2000
+	maxTitleChars       = 256
+	maxDescriptionChars = 4096
+	maxFields           = 25
+	maxFieldValueChars  = 1024
+	maxFooterChars      = 2048
+)
+
+// emptyFieldName is used for the items, since the name of a field is required.
+const emptyFieldName = "\u200b"
+
+// Message is the payload of a webhook,
+// see: https://discord.com/developers/docs/resources/webhook#execute-webhook
+type Message struct {
+	Username  string  `json:"username,omitempty"`
+	AvatarURL string  `json:"avatar_url,omitempty"`
+	Content   string  `json:"content,omitempty"`
+	Embeds    []Embed `json:"embeds,omitempty"`
+}
+
+type Embed struct {
+	Title       string  `json:"title,omitempty"`
+	URL         string  `json:"url,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Color       int     `json:"color,omitempty"`
+	Fields      []Field `json:"fields,omitempty"`
+	Thumbnail   *Image  `json:"thumbnail,omitempty"`
+	Footer      *Footer `json:"footer,omitempty"`
+	Timestamp   string  `json:"timestamp,omitempty"`
+}
+
+type Field struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type Image struct {
+	URL string `json:"url"`
+}
+
+type Footer struct {
+	Text    string `json:"text"`
+	IconURL string `json:"icon_url,omitempty"`
+}
+
+// size returns the number of the characters counted by Discord.
+func (e Embed) size() int {
+	n := utf8.RuneCountInString(e.Title) + utf8.RuneCountInString(e.Description)
+
+	for _, f := range e.Fields {
+		n += utf8.RuneCountInString(f.Name) + utf8.RuneCountInString(f.Value)
+	}
+
+	if e.Footer != nil {
+		n += utf8.RuneCountInString(e.Footer.Text)
+	}
+
+	return n
+}
+
+// MentionFunc returns the Discord mention of the user, i.e. "<@80351110224678912>".
+type MentionFunc func(reminder.User) string
+
+// Render renders the reminder into the messages, each section becomes
+// an embed, where the items are rendered as the fields, the groups into
+// the description and the mentions into the content. The messages are
+// split by the limits of Discord, the sections having more fields than
+// an embed can carry are continued in the next embeds.
+func Render(r *reminder.Reminder, mention MentionFunc) []Message {
+	var messages []Message
+
+	var current Message
+
+	size := 0
+
+	flush := func() {
+		if len(current.Embeds) > 0 || current.Content != "" {
+			messages = append(messages, current)
+		}
+
+		current = Message{}
+		size = 0
+	}
+
+	for _, s := range r.Sections {
+		mentions := alerters.Markdown.WithMention(mention).Mentions(s.Mentions)
+
+		for i, e := range renderSection(s, mention) {
+			if len(current.Embeds) == maxEmbeds || size+e.size() > maxMessageChars {
+				flush()
+			}
+
+			// the mentions are sent with the first embed of the section
+			if i == 0 && mentions != "" {
+				if utf8.RuneCountInString(current.Content)+utf8.RuneCountInString(mentions)+1 > maxContentChars {
+					flush()
+				}
+
+				current.Content = strings.TrimSpace(current.Content + " " + mentions)
+			}
+
+			current.Embeds = append(current.Embeds, e)
+			size += e.size()
+		}
+	}
+
+	flush()
+
+	return messages
+}
+
+// renderSection renders the section into an embed, and the
+// continuation ones if the fields do not fit into a single one.
+func renderSection(s reminder.Section, mention MentionFunc) []Embed {
+	first := Embed{
+		Title: truncate(s.Title, maxTitleChars),
+		URL:   s.Link,
+		Color: color(s.Severity),
+	}
+
+	if s.Icon != "" {
+		first.Thumbnail = &Image{URL: s.Icon}
+	}
+
+	var footer *Footer
+
+	if s.Footer != "" {
+		footer = &Footer{Text: truncate(s.Footer, maxFooterChars), IconURL: s.FooterIcon}
+	}
+
+	var timestamp string
+
+	if !s.Timestamp.IsZero() {
+		timestamp = s.Timestamp.UTC().Format(time.RFC3339)
+	}
+
+	// the footer is sent with the last embed, keep room for it
+	reserved := 0
+	if footer != nil {
+		reserved = utf8.RuneCountInString(footer.Text)
+	}
+
+	first.Description = truncate(description(s, mention), minInt(maxDescriptionChars, maxMessageChars-reserved-first.size()))
+
+	var fields []Field
+
+	for _, item := range s.Items {
+		fields = append(fields, Field{Name: emptyFieldName, Value: truncate(FormatItem(item, mention), maxFieldValueChars)})
+	}
+
+	if more := s.Overflow(); more != nil {
+		fields = append(fields, Field{Name: emptyFieldName, Value: FormatText(more)})
+	}
+
+	embeds := []Embed{first}
+
+	for _, f := range fields {
+		last := &embeds[len(embeds)-1]
+
+		if len(last.Fields) == maxFields || last.size()+reserved+utf8.RuneCountInString(f.Name+f.Value) > maxMessageChars {
+			embeds = append(embeds, Embed{Color: first.Color})
+			last = &embeds[len(embeds)-1]
+		}
+
+		last.Fields = append(last.Fields, f)
+	}
+
+	last := &embeds[len(embeds)-1]
+	last.Footer = footer
+	last.Timestamp = timestamp
+
+	return embeds
+}
+
+// description renders the summary and the groups of the section.
+func description(s reminder.Section, mention MentionFunc) string {
+	var text strings.Builder
+
+	if len(s.Summary) > 0 {
+		text.WriteString(FormatText(s.Summary))
+		text.WriteString("\n")
+	}
+
+	for i, g := range s.Groups {
+		if i > 0 {
+			text.WriteString("\n")
+		}
+
+		text.WriteString(fmt.Sprintf("\n**%s:**", g.Title))
+
+		for _, item := range g.Items {
+			text.WriteString("\n")
+			text.WriteString(FormatItem(item, mention))
+		}
+	}
+
+	return strings.TrimSpace(text.String())
+}
+
+// FormatItem renders the item as a single line, i.e.
+// "✓ [title](link) (created 2 days ago) by <@80351110224678912>, reviewers: bar".
+func FormatItem(item reminder.Item, mention MentionFunc) string {
+	return alerters.Markdown.WithMention(mention).Item(item)
+}
+
+// FormatText renders the text in Discord Markdown.
+func FormatText(t reminder.Text) string {
+	return alerters.Markdown.Text(t)
+}
+
+// truncate cuts the text to n characters, ending with "…" if it is cut.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+
+	if n <= 0 {
+		return ""
+	}
+
+	return string([]rune(s)[:n-1]) + "…"
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+// color returns the colors of the alerters as an integer,
+// since the embed colors are decimal in Discord.
+func color(s reminder.Severity) int {
+	v, _ := strconv.ParseInt(strings.TrimPrefix(alerters.Color(s), "#"), 16, 32)
+
+	return int(v)
+}
@@ -0,0 +1,441 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package email
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Dentrax/remind-us/pkg/alerters"
+	"github.com/Dentrax/remind-us/pkg/config"
+	"github.com/Dentrax/remind-us/pkg/reminder"
+	"github.com/pkg/errors"
+)
+
+var errAlert = errors.New("email is not loaded")
+
+const (
+	defaultPort    = 587
+	defaultSubject = "{{.Source}} reminder"
+	defaultTimeout = 30 * time.Second
+)
+
+// TLS is how the connection is secured.
+type TLS string
+
+const (
+	// TLSAuto upgrades the connection by STARTTLS if the server supports it.
+	TLSAuto     TLS = ""
+	TLSStartTLS TLS = "starttls"
+	// TLSImplicit connects over TLS, i.e. on port 465.
+	TLSImplicit TLS = "tls"
+	TLSNone     TLS = "none"
+)
+
+type Email struct {
+	config  *config.EmailAlertConfig
+	tls     TLS
+	subject *template.Template
+
+	// channels stores the recipients of the channels.
+	//
+	// map: K: lower-cased Channel, V: Recipients
+	channels map[string][]string
+
+	loaded bool
+}
+
+// Subject is the data of the subject template.
+type Subject struct {
+	// Source is the name of the integration, i.e. "GitLab".
+	Source   string
+	Title    string
+	Sections int
+	Items    int
+	Date     string
+}
+
+func (e *Email) Name() string {
+	return "Email"
+}
+
+func (e *Email) Enabled(config config.AlertConfig) bool {
+	if config.Email == nil {
+		return false
+	}
+
+	if config.Email.Enabled == "" {
+		return true
+	}
+
+	v, _ := strconv.ParseBool(config.Email.Enabled)
+
+	return v
+}
+
+func (e *Email) Load(config config.AlertConfig) error {
+	c := config.Email
+
+	if c.Host == "" {
+		return errors.New("email host is required")
+	}
+
+	if _, err := mail.ParseAddress(c.From); err != nil {
+		return errors.Wrapf(err, "incorrect 'from' address: '%s'", c.From)
+	}
+
+	for _, to := range c.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			return errors.Wrapf(err, "incorrect 'to' address: '%s'", to)
+		}
+	}
+
+	switch t := TLS(strings.ToLower(c.TLS)); t {
+	case TLSAuto, TLSStartTLS, TLSImplicit, TLSNone:
+		e.tls = t
+	default:
+		return errors.Errorf("unknown email TLS: '%s'", c.TLS)
+	}
+
+	subject := c.Subject
+	if subject == "" {
+		subject = defaultSubject
+	}
+
+	tmpl, err := template.New("subject").Parse(subject)
+	if err != nil {
+		return errors.Wrapf(err, "incorrect 'subject' template: '%s'", subject)
+	}
+
+	channels := make(map[string][]string, len(c.Channels))
+
+	for k, v := range c.Channels {
+		channels[strings.ToLower(k)] = v
+	}
+
+	e.config = c
+	e.subject = tmpl
+	e.channels = channels
+	e.loaded = true
+
+	return nil
+}
+
+func (e *Email) Alert(r *reminder.Reminder) error {
+	if !e.loaded {
+		return errAlert
+	}
+
+	// sections are sent to the recipients of their channels, if any
+	for _, channel := range r.Channels() {
+		to, ok := e.channels[strings.ToLower(channel)]
+		if !ok {
+			to = e.config.To
+		}
+
+		if len(to) == 0 {
+			log.Printf("no email recipient for channel: '%s', skipped\n", channel)
+
+			continue
+		}
+
+		if err := e.send(to, r.ForChannel(channel)); err != nil {
+			return errors.Wrap(err, "unable to send email during alerting")
+		}
+	}
+
+	return e.alertDirect(r.Source, r.Direct())
+}
+
+// alertDirect sends each personal section to the email of its
+// recipient, if it is enabled. The ones without an email are skipped.
+func (e *Email) alertDirect(source string, sections []reminder.Section) error {
+	if len(sections) == 0 {
+		return nil
+	}
+
+	if !e.config.DirectMessages {
+		alerters.SkipDirect(sections, "direct messages are disabled")
+
+		return nil
+	}
+
+	for _, s := range sections {
+		if s.Recipient.Email == "" {
+			log.Printf("no email of user: '%s', direct message skipped\n", s.Recipient.Username)
+
+			continue
+		}
+
+		r := &reminder.Reminder{Source: source, Sections: []reminder.Section{s}}
+
+		if err := e.send([]string{s.Recipient.Email}, r); err != nil {
+			return errors.Wrapf(err, "unable to send direct email to: '%s'", s.Recipient.Username)
+		}
+	}
+
+	return nil
+}
+
+func (e *Email) send(to []string, r *reminder.Reminder) error {
+	msg, err := e.Compose(to, r)
+	if err != nil {
+		return err
+	}
+
+	c, err := e.dial()
+	if err != nil {
+		return err
+	}
+
+	defer c.Close()
+
+	if e.config.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", e.config.Username, e.config.Password, e.config.Host)); err != nil {
+			return errors.Wrap(err, "unable to authenticate")
+		}
+	}
+
+	from, _ := mail.ParseAddress(e.config.From)
+
+	if err := c.Mail(from.Address); err != nil {
+		return err
+	}
+
+	for _, addr := range to {
+		rcpt, err := mail.ParseAddress(addr)
+		if err != nil {
+			return errors.Wrapf(err, "incorrect recipient address: '%s'", addr)
+		}
+
+		if err := c.Rcpt(rcpt.Address); err != nil {
+			return errors.Wrapf(err, "recipient is rejected: '%s'", addr)
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
+
+// dial connects to the server, and secures the connection by the config.
+func (e *Email) dial() (*smtp.Client, error) {
+	port := e.config.Port
+	if port == 0 {
+		port = defaultPort
+	}
+
+	addr := net.JoinHostPort(e.config.Host, strconv.Itoa(port))
+
+	// #nosec G402 -- the verification is skipped only if it is configured
+	tlsConfig := &tls.Config{
+		ServerName:         e.config.Host,
+		InsecureSkipVerify: e.config.SkipVerify,
+	}
+
+	dialer := &net.Dialer{Timeout: defaultTimeout}
+
+	var conn net.Conn
+
+	var err error
+
+	if e.tls == TLSImplicit {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to connect to: '%s'", addr)
+	}
+
+	c, err := smtp.NewClient(conn, e.config.Host)
+	if err != nil {
+		conn.Close()
+
+		return nil, errors.Wrapf(err, "unable to connect to: '%s'", addr)
+	}
+
+	if e.tls == TLSStartTLS || e.tls == TLSAuto {
+		ok, _ := c.Extension("STARTTLS")
+
+		if !ok && e.tls == TLSStartTLS {
+			c.Close()
+
+			return nil, errors.Errorf("STARTTLS is not supported by: '%s'", addr)
+		}
+
+		if ok {
+			if err := c.StartTLS(tlsConfig); err != nil {
+				c.Close()
+
+				return nil, errors.Wrap(err, "unable to start TLS")
+			}
+		}
+	}
+
+	return c, nil
+}
+
+// Compose composes the multipart email of the reminder,
+// with the HTML and the plaintext bodies.
+func (e *Email) Compose(to []string, r *reminder.Reminder) ([]byte, error) {
+	subject, err := e.Subject(r)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := RenderHTML(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to render HTML")
+	}
+
+	from, err := mail.ParseAddress(e.config.From)
+	if err != nil {
+		return nil, errors.Wrapf(err, "incorrect 'from' address: '%s'", e.config.From)
+	}
+
+	recipients := make([]string, len(to))
+
+	for i, addr := range to {
+		rcpt, err := mail.ParseAddress(addr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "incorrect recipient address: '%s'", addr)
+		}
+
+		recipients[i] = rcpt.String()
+	}
+
+	var b bytes.Buffer
+
+	w := multipart.NewWriter(&b)
+
+	header := []string{
+		fmt.Sprintf("From: %s", from.String()),
+		fmt.Sprintf("To: %s", strings.Join(recipients, ", ")),
+		fmt.Sprintf("Subject: %s", mime.QEncoding.Encode("utf-8", subject)),
+		fmt.Sprintf("Date: %s", time.Now().Format(time.RFC1123Z)),
+		fmt.Sprintf("Message-ID: %s", messageID(from.Address)),
+		"MIME-Version: 1.0",
+		fmt.Sprintf("Content-Type: multipart/alternative; boundary=%s", w.Boundary()),
+	}
+
+	b.WriteString(strings.Join(header, "\r\n"))
+	b.WriteString("\r\n\r\n")
+
+	// the last part is the preferred one
+	for _, part := range []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=utf-8", RenderText(r)},
+		{"text/html; charset=utf-8", body},
+	} {
+		pw, err := w.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		qw := quotedprintable.NewWriter(pw)
+
+		if _, err := qw.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+
+		if err := qw.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// Subject renders the subject template of the reminder.
+func (e *Email) Subject(r *reminder.Reminder) (string, error) {
+	data := Subject{
+		Source:   r.Source,
+		Sections: len(r.Sections),
+		Date:     time.Now().Format("2006-01-02"),
+	}
+
+	if len(r.Sections) > 0 {
+		data.Title = r.Sections[0].Title
+	}
+
+	for _, s := range r.Sections {
+		data.Items += len(s.Items)
+
+		for _, g := range s.Groups {
+			data.Items += len(g.Items)
+		}
+	}
+
+	var b strings.Builder
+
+	if err := e.subject.Execute(&b, data); err != nil {
+		return "", errors.Wrap(err, "unable to render subject")
+	}
+
+	return strings.TrimSpace(b.String()), nil
+}
+
+// messageID returns a unique Message-ID on the domain of the sender.
+func messageID(from string) string {
+	domain := "remind-us"
+
+	if i := strings.LastIndex(from, "@"); i >= 0 {
+		domain = from[i+1:]
+	}
+
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(b), domain)
+}
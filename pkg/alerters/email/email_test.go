@@ -0,0 +1,396 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package email
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"io/ioutil"
+	"math/big"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Dentrax/remind-us/pkg/config"
+	"github.com/Dentrax/remind-us/pkg/reminder"
+	"github.com/stretchr/testify/assert"
+)
+
+// received is a message received by the SMTP stand-in.
+type received struct {
+	auth    string
+	from    string
+	to      []string
+	data    string
+	secured bool
+}
+
+// smtpServer is a local SMTP stand-in, it accepts everything and
+// advertises STARTTLS if a TLS config is given.
+type smtpServer struct {
+	listener net.Listener
+	tls      *tls.Config
+
+	mu       sync.Mutex
+	messages []received
+}
+
+func newSMTPServer(t *testing.T, tlsConfig *tls.Config) *smtpServer {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	s := &smtpServer{listener: l, tls: tlsConfig}
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			go s.serve(conn)
+		}
+	}()
+
+	t.Cleanup(func() { _ = l.Close() })
+
+	return s
+}
+
+func (s *smtpServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpServer) received() []received {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]received(nil), s.messages...)
+}
+
+func (s *smtpServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	tp := textproto.NewConn(conn)
+
+	var msg received
+
+	_ = tp.PrintfLine("220 localhost ESMTP")
+
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+
+		args := strings.SplitN(line, " ", 2)
+
+		switch strings.ToUpper(args[0]) {
+		case "EHLO", "HELO":
+			if s.tls != nil && !msg.secured {
+				_ = tp.PrintfLine("250-localhost\r\n250-STARTTLS\r\n250 AUTH PLAIN")
+			} else {
+				_ = tp.PrintfLine("250-localhost\r\n250 AUTH PLAIN")
+			}
+		case "STARTTLS":
+			_ = tp.PrintfLine("220 ready")
+
+			c := tls.Server(conn, s.tls)
+			if err := c.Handshake(); err != nil {
+				return
+			}
+
+			tp = textproto.NewConn(c)
+			msg.secured = true
+		case "AUTH":
+			b, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(args[1], "PLAIN "))
+			msg.auth = string(b)
+			_ = tp.PrintfLine("235 OK")
+		case "MAIL":
+			msg.from = strings.Trim(strings.TrimPrefix(args[1], "FROM:"), "<>")
+			_ = tp.PrintfLine("250 OK")
+		case "RCPT":
+			msg.to = append(msg.to, strings.Trim(strings.TrimPrefix(args[1], "TO:"), "<>"))
+			_ = tp.PrintfLine("250 OK")
+		case "DATA":
+			_ = tp.PrintfLine("354 go ahead")
+
+			b, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+
+			msg.data = string(b)
+
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+
+			_ = tp.PrintfLine("250 OK")
+		case "QUIT":
+			_ = tp.PrintfLine("221 bye")
+			return
+		default:
+			_ = tp.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+// selfSigned returns a TLS config of a self-signed certificate of 127.0.0.1.
+func selfSigned(t *testing.T) *tls.Config {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+		MinVersion:   tls.VersionTLS12,
+	}
+}
+
+// parts returns the subject and the bodies of the message by their content types.
+func parts(t *testing.T, data string) (string, map[string]string) {
+	t.Helper()
+
+	m, err := mail.ReadMessage(strings.NewReader(data))
+	assert.NoError(t, err)
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(m.Header.Get("Subject"))
+	assert.NoError(t, err)
+
+	mediaType, params, err := mime.ParseMediaType(m.Header.Get("Content-Type"))
+	assert.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	result := make(map[string]string)
+
+	r := multipart.NewReader(m.Body, params["boundary"])
+
+	for {
+		p, err := r.NextPart()
+		if err != nil {
+			break
+		}
+
+		b, err := ioutil.ReadAll(p)
+		assert.NoError(t, err)
+
+		contentType, _, _ := mime.ParseMediaType(p.Header.Get("Content-Type"))
+		result[contentType] = string(b)
+	}
+
+	return subject, result
+}
+
+func TestEmail_Alert(t *testing.T) {
+	t.Parallel()
+
+	s := newSMTPServer(t, nil)
+
+	e := &Email{}
+
+	assert.True(t, e.Enabled(config.AlertConfig{Email: &config.EmailAlertConfig{}}))
+	assert.False(t, e.Enabled(config.AlertConfig{Email: &config.EmailAlertConfig{Enabled: "false"}}))
+	assert.Equal(t, errAlert, e.Alert(&reminder.Reminder{}))
+
+	err := e.Load(config.AlertConfig{Email: &config.EmailAlertConfig{
+		Host:           "127.0.0.1",
+		Port:           s.port(),
+		Username:       "user",
+		Password:       "pass",
+		From:           "Remind Us <remind-us@example.com>",
+		To:             []string{"manager@example.com", "Lead <lead@example.com>"},
+		Subject:        "{{.Source}}: {{.Items}} item(s) in {{.Title}}",
+		Channels:       map[string][]string{"#Security": {"security@example.com"}},
+		DirectMessages: true,
+	}})
+	assert.NoError(t, err)
+
+	// the multipart email of the sections, to the recipients of the channels and the users
+	r := &reminder.Reminder{
+		Source: "GitLab",
+		Sections: []reminder.Section{
+			{
+				Title:    "baz",
+				Link:     "https://gitlab.com/foo/baz",
+				Summary:  reminder.Text{reminder.Plain("There are "), reminder.Link("2 open MRs", "https://gitlab.com/foo/baz/merge_requests?state=opened")},
+				Severity: reminder.SeverityWarning,
+				Groups: []reminder.Group{
+					{
+						Title: "2 MRs are awaiting review",
+						Items: []reminder.Item{
+							{
+								Status:    reminder.StatusOK,
+								Title:     "Add <email> alerter",
+								Link:      "https://gitlab.com/foo/baz/-/merge_requests/1",
+								Details:   reminder.Text{reminder.Plain("(created "), reminder.Bold("3 days"), reminder.Plain(" ago)")},
+								Author:    &reminder.User{Username: "foo", Name: "Foo"},
+								Reviewers: []reminder.User{{Username: "bar"}},
+							},
+							{Title: "Fix typo", Link: "https://gitlab.com/foo/baz/-/merge_requests/2"},
+						},
+					},
+				},
+				Footer: "foo",
+			},
+			{Title: "security", Channel: "#security", Items: []reminder.Item{{Title: "CVE", Link: "https://example.com/cve"}}},
+			{Title: "GitLab digest of foo", Recipient: &reminder.User{Username: "foo", Email: "foo@example.com"}},
+			{Title: "GitLab digest of bar", Recipient: &reminder.User{Username: "bar"}},
+		},
+	}
+
+	assert.NoError(t, e.Alert(r))
+
+	got := s.received()
+	assert.Len(t, got, 3)
+
+	assert.Equal(t, "\x00user\x00pass", got[0].auth)
+	assert.Equal(t, "remind-us@example.com", got[0].from)
+	assert.Equal(t, []string{"manager@example.com", "lead@example.com"}, got[0].to)
+	assert.False(t, got[0].secured)
+
+	subject, bodies := parts(t, got[0].data)
+	assert.Equal(t, "GitLab: 2 item(s) in baz", subject)
+
+	assert.Contains(t, bodies["text/plain"], "There are 2 open MRs <https://gitlab.com/foo/baz/merge_requests?state=opened>")
+	assert.Contains(t, bodies["text/plain"], "✓ Add <email> alerter <https://gitlab.com/foo/baz/-/merge_requests/1> (created 3 days ago) by Foo, reviewers: bar")
+
+	assert.Contains(t, bodies["text/html"], `<a href="https://gitlab.com/foo/baz/-/merge_requests/1">Add &lt;email&gt; alerter</a> (created <b>3 days</b> ago) by Foo, reviewers: bar`)
+	assert.Contains(t, bodies["text/html"], "border-left: 4px solid #DAA038")
+	assert.NotContains(t, bodies["text/html"], "security")
+
+	assert.Equal(t, []string{"security@example.com"}, got[1].to)
+
+	subject, _ = parts(t, got[1].data)
+	assert.Equal(t, "GitLab: 1 item(s) in security", subject)
+
+	// the digest of bar is skipped, bar has no email
+	assert.Equal(t, []string{"foo@example.com"}, got[2].to)
+}
+
+func TestEmail_Alert_StartTLS(t *testing.T) {
+	t.Parallel()
+
+	s := newSMTPServer(t, selfSigned(t))
+
+	e := &Email{}
+
+	err := e.Load(config.AlertConfig{Email: &config.EmailAlertConfig{
+		Host:       "127.0.0.1",
+		Port:       s.port(),
+		TLS:        "starttls",
+		SkipVerify: true,
+		From:       "remind-us@example.com",
+		To:         []string{"manager@example.com"},
+	}})
+	assert.NoError(t, err)
+
+	assert.NoError(t, e.Alert(&reminder.Reminder{Source: "RSS", Sections: []reminder.Section{{Title: "foo"}}}))
+
+	got := s.received()
+	assert.Len(t, got, 1)
+	assert.True(t, got[0].secured)
+
+	subject, _ := parts(t, got[0].data)
+	assert.Equal(t, "RSS reminder", subject)
+
+	// STARTTLS is required but not supported
+	plain := newSMTPServer(t, nil)
+
+	e.config.Port = plain.port()
+
+	err = e.Alert(&reminder.Reminder{Sections: []reminder.Section{{Title: "foo"}}})
+	assert.EqualError(t, err, "unable to send email during alerting: STARTTLS is not supported by: '127.0.0.1:"+strconv.Itoa(plain.port())+"'")
+}
+
+func TestEmail_Load_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		config  config.EmailAlertConfig
+		wantErr string
+	}{
+		{"no host", config.EmailAlertConfig{From: "foo@example.com"}, "email host is required"},
+		{"from", config.EmailAlertConfig{Host: "localhost", From: "foo"}, "incorrect 'from' address: 'foo': mail: missing '@' or angle-addr"},
+		{"tls", config.EmailAlertConfig{Host: "localhost", From: "foo@example.com", TLS: "ssl"}, "unknown email TLS: 'ssl'"},
+		{"subject", config.EmailAlertConfig{Host: "localhost", From: "foo@example.com", Subject: "{{.Source"}, "incorrect 'subject' template: '{{.Source': template: subject:1: unclosed action"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := tt.config
+
+			assert.EqualError(t, (&Email{}).Load(config.AlertConfig{Email: &c}), tt.wantErr)
+		})
+	}
+}
+
+func TestRenderHTML_Links(t *testing.T) {
+	t.Parallel()
+
+	got, err := RenderHTML(&reminder.Reminder{
+		Sections: []reminder.Section{
+			{
+				Title:    "foo",
+				Link:     "javascript:alert(1)",
+				Summary:  reminder.Text{reminder.Link("bar", "data:text/html,baz"), reminder.Plain(" & "), reminder.Bold("qux")},
+				Severity: reminder.SeverityCritical,
+				Items: []reminder.Item{
+					{Title: "CVE", Link: "javascript:alert(2)"},
+					{Title: "Add <email>", Link: "https://example.com/?a=1&b=2", Details: reminder.Text{reminder.Link("mail", "mailto:foo@example.com")}},
+				},
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	assert.NotContains(t, got, "javascript:")
+	assert.NotContains(t, got, "data:")
+	assert.Contains(t, got, `<h3 style="margin: 4px 0;"><a href="#ZgotmplZ">foo</a></h3>`)
+	assert.Contains(t, got, `<p><a href="#ZgotmplZ">bar</a> &amp; <b>qux</b></p>`)
+	assert.Contains(t, got, `<li>• <a href="#ZgotmplZ">CVE</a> </li>`)
+	assert.Contains(t, got, `<li>• <a href="https://example.com/?a=1&amp;b=2">Add &lt;email&gt;</a> <a href="mailto:foo@example.com">mail</a></li>`)
+	assert.Contains(t, got, "border-left: 4px solid #A30200;")
+}
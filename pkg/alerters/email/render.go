@@ -0,0 +1,204 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package email
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/Dentrax/remind-us/pkg/alerters"
+	"github.com/Dentrax/remind-us/pkg/reminder"
+)
+
+// htmlTemplate renders the links as URL values, so the unsafe
+// ones, i.e. "javascript:", get sanitized by html/template.
+var htmlTemplate = template.Must(template.New("email").Funcs(template.FuncMap{
+	"color": color,
+	"users": formatUsers,
+}).Parse(`{{define "text"}}{{range .}}{{if .Link}}<a href="{{.Link}}">{{template "span" .}}</a>{{else}}{{template "span" .}}{{end}}{{end}}{{end}}
+{{- define "span"}}{{if .Bold}}<b>{{.Text}}</b>{{else}}{{.Text}}{{end}}{{end}}
+{{- define "item"}}{{printf "%c" .Status.Marker}} <a href="{{.Link}}">{{.Title}}</a> {{template "text" .Details}}{{users .}}{{end -}}
+<!DOCTYPE html>
+<html>
+<body style="font-family: -apple-system, Helvetica, Arial, sans-serif; font-size: 14px;">
+{{- range .Sections}}
+<div style="border-left: 4px solid {{color .Severity}}; padding: 4px 12px; margin: 12px 0;">
+{{- if .Link}}
+<h3 style="margin: 4px 0;"><a href="{{.Link}}">{{.Title}}</a></h3>
+{{- else}}
+<h3 style="margin: 4px 0;">{{.Title}}</h3>
+{{- end}}
+{{- if .Summary}}
+<p>{{template "text" .Summary}}</p>
+{{- end}}
+{{- range .Groups}}
+<p><b>{{.Title}}:</b></p>
+<ul>
+{{- range .Items}}
+<li>{{template "item" .}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if .Items}}
+<ul>
+{{- range .Items}}
+<li>{{template "item" .}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- with .Overflow}}
+<p>{{template "text" .}}</p>
+{{- end}}
+{{- if .Footer}}
+<p style="color: #888888; font-size: 12px;">{{.Footer}}{{if not .Timestamp.IsZero}} | {{.Timestamp.UTC.Format "2006-01-02 15:04 MST"}}{{end}}</p>
+{{- end}}
+</div>
+{{- end}}
+</body>
+</html>
+`))
+
+// RenderHTML renders the reminder into an HTML body, each
+// section becomes a block bordered by the color of its severity.
+func RenderHTML(r *reminder.Reminder) (string, error) {
+	var b bytes.Buffer
+
+	if err := htmlTemplate.Execute(&b, r); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+// RenderText renders the reminder into a plaintext body,
+// the links are written next to their texts.
+func RenderText(r *reminder.Reminder) string {
+	var b strings.Builder
+
+	for i, s := range r.Sections {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+
+		b.WriteString(s.Title)
+
+		if s.Link != "" {
+			b.WriteString(fmt.Sprintf(" <%s>", s.Link))
+		}
+
+		b.WriteString("\n")
+
+		if len(s.Summary) > 0 {
+			b.WriteString(FormatText(s.Summary))
+			b.WriteString("\n")
+		}
+
+		for _, g := range s.Groups {
+			b.WriteString(fmt.Sprintf("\n%s:\n", g.Title))
+
+			for _, item := range g.Items {
+				b.WriteString(FormatItem(item))
+				b.WriteString("\n")
+			}
+		}
+
+		if len(s.Items) > 0 {
+			b.WriteString("\n")
+
+			for _, item := range s.Items {
+				b.WriteString(FormatItem(item))
+				b.WriteString("\n")
+			}
+		}
+
+		if more := s.Overflow(); more != nil {
+			b.WriteString(FormatText(more))
+			b.WriteString("\n")
+		}
+
+		if s.Footer != "" {
+			b.WriteString(fmt.Sprintf("-- %s\n", s.Footer))
+		}
+	}
+
+	return b.String()
+}
+
+// markup renders the texts in plaintext, the links are written
+// next to their texts and the users are written by formatUsers.
+var markup = alerters.Markup{
+	Link: func(text, url string) string { return fmt.Sprintf("%s <%s>", text, url) },
+	Bold: func(text string) string { return text },
+}
+
+// FormatItem renders the item as a single plaintext line, i.e.
+// "✓ title <link> (created 2 days ago) by Furkan Türkal, reviewers: dentrax".
+func FormatItem(item reminder.Item) string {
+	return strings.TrimSpace(markup.Item(item) + formatUsers(item))
+}
+
+// FormatText renders the text in plaintext, i.e. "1 open MR <link>".
+func FormatText(t reminder.Text) string {
+	return markup.Text(t)
+}
+
+// formatUsers writes the author, the assignees and
+// the reviewers of the item by their names.
+func formatUsers(item reminder.Item) string {
+	var b strings.Builder
+
+	if item.Author != nil {
+		b.WriteString(fmt.Sprintf(" by %s", name(*item.Author)))
+	}
+
+	if n := names(item.OtherAssignees()); n != "" {
+		b.WriteString(fmt.Sprintf(", assignees: %s", n))
+	}
+
+	if n := names(item.OtherReviewers()); n != "" {
+		b.WriteString(fmt.Sprintf(", reviewers: %s", n))
+	}
+
+	return b.String()
+}
+
+// names joins the names of the users.
+func names(users []reminder.User) string {
+	result := make([]string, 0, len(users))
+
+	for _, u := range users {
+		result = append(result, name(u))
+	}
+
+	return strings.Join(result, ", ")
+}
+
+func name(u reminder.User) string {
+	if u.Name != "" {
+		return u.Name
+	}
+
+	return u.Username
+}
+
+// color returns the colors of the alerters, to look the same.
+func color(s reminder.Severity) template.CSS {
+	// #nosec G203 -- the colors are constant
+	return template.CSS(alerters.Color(s))
+}
@@ -0,0 +1,116 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alerters
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/Dentrax/remind-us/pkg/reminder"
+)
+
+// Markup is the syntax of an alerter, the texts and the
+// items of the reminders are rendered by it.
+type Markup struct {
+	// Link renders the text linking to the URL, i.e. "<url|text>".
+	Link func(text, url string) string
+	// Bold renders the text in bold, i.e. "*text*".
+	Bold func(text string) string
+	// Heading renders the titles of the groups in the attachments,
+	// they are written as they are if it is nil.
+	Heading func(text string) string
+	// Mention renders the mention of the user, i.e. "<@U024BE7LH>".
+	// The users of the items are omitted if it is nil.
+	Mention func(reminder.User) string
+}
+
+// Markdown renders the links and the bold texts in Markdown.
+var Markdown = Markup{
+	Link: func(text, url string) string { return fmt.Sprintf("[%s](%s)", text, url) },
+	Bold: func(text string) string { return fmt.Sprintf("**%s**", text) },
+}
+
+// WithMention returns a copy of the markup mentioning the users by the given func.
+func (m Markup) WithMention(mention func(reminder.User) string) Markup {
+	m.Mention = mention
+
+	return m
+}
+
+// Text renders the text by the markup.
+func (m Markup) Text(t reminder.Text) string {
+	return t.Format(func(s reminder.Span) string {
+		text := s.Text
+
+		if s.Bold {
+			text = m.Bold(text)
+		}
+
+		if s.Link != "" {
+			return m.Link(text, s.Link)
+		}
+
+		return text
+	})
+}
+
+// Mentions mentions the users, separated by a space.
+func (m Markup) Mentions(users []reminder.User) string {
+	if m.Mention == nil {
+		return ""
+	}
+
+	mentions := make([]string, 0, len(users))
+
+	for _, u := range users {
+		mentions = append(mentions, m.Mention(u))
+	}
+
+	return strings.Join(mentions, " ")
+}
+
+// Item renders the item as a single line, i.e.
+// "✓ <link|title> (created 2 days ago) by <@U024BE7LH>, reviewers: <@U0G9QF9C6>".
+func (m Markup) Item(item reminder.Item) string {
+	line := fmt.Sprintf("%c %s %s", item.Status.Marker(), m.Link(item.Title, item.Link), m.Text(item.Details))
+
+	if m.Mention == nil {
+		return line
+	}
+
+	if item.Author != nil {
+		line += fmt.Sprintf(" by %s", m.Mention(*item.Author))
+	}
+
+	if assignees := item.OtherAssignees(); len(assignees) > 0 {
+		line += fmt.Sprintf(", assignees: %s", m.Mentions(assignees))
+	}
+
+	if reviewers := item.OtherReviewers(); len(reviewers) > 0 {
+		line += fmt.Sprintf(", reviewers: %s", m.Mentions(reviewers))
+	}
+
+	return line
+}
+
+// SkipDirect logs the direct sections skipped by the alerter, by the reason.
+func SkipDirect(sections []reminder.Section, reason string) {
+	if len(sections) > 0 {
+		log.Printf("%d direct section(s) skipped, %s\n", len(sections), reason)
+	}
+}
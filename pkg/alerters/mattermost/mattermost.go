@@ -0,0 +1,257 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mattermost
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Dentrax/remind-us/pkg/alerters"
+	"github.com/Dentrax/remind-us/pkg/config"
+	"github.com/Dentrax/remind-us/pkg/reminder"
+	"github.com/pkg/errors"
+)
+
+var errAlert = errors.New("mattermost is not loaded")
+
+const defaultTimeout = 30 * time.Second
+
+// Message is the payload of an incoming webhook,
+// see: https://docs.mattermost.com/developer/webhooks-incoming.html
+type Message struct {
+	Text        string       `json:"text,omitempty"`
+	Channel     string       `json:"channel,omitempty"`
+	Username    string       `json:"username,omitempty"`
+	IconURL     string       `json:"icon_url,omitempty"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+// Attachment is a message attachment, unlike Slack the colors are hex
+// codes, the texts are Markdown, and there is no timestamp.
+// See: https://docs.mattermost.com/developer/message-attachments.html
+type Attachment struct {
+	Fallback   string  `json:"fallback,omitempty"`
+	Color      string  `json:"color,omitempty"`
+	Pretext    string  `json:"pretext,omitempty"`
+	Text       string  `json:"text,omitempty"`
+	AuthorName string  `json:"author_name,omitempty"`
+	AuthorLink string  `json:"author_link,omitempty"`
+	AuthorIcon string  `json:"author_icon,omitempty"`
+	Fields     []Field `json:"fields,omitempty"`
+	Footer     string  `json:"footer,omitempty"`
+	FooterIcon string  `json:"footer_icon,omitempty"`
+}
+
+type Field struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+type Mattermost struct {
+	config *config.MattermostAlertConfig
+
+	// users stores the Mattermost usernames.
+	//
+	// map: K: lower-cased Username or Email, V: Mattermost username
+	users map[string]string
+
+	client *http.Client
+	loaded bool
+}
+
+func (m *Mattermost) Name() string {
+	return "Mattermost"
+}
+
+func (m *Mattermost) Enabled(config config.AlertConfig) bool {
+	if config.Mattermost == nil {
+		return false
+	}
+
+	if config.Mattermost.Enabled == "" {
+		return true
+	}
+
+	v, _ := strconv.ParseBool(config.Mattermost.Enabled)
+
+	return v
+}
+
+func (m *Mattermost) Load(config config.AlertConfig) error {
+	if _, err := url.ParseRequestURI(config.Mattermost.Webhook); err != nil {
+		return errors.Wrapf(err, "incorrect 'webhook' pattern: '%s'", config.Mattermost.Webhook)
+	}
+
+	users := make(map[string]string, len(config.Mattermost.Users))
+
+	for k, v := range config.Mattermost.Users {
+		users[strings.ToLower(k)] = strings.TrimPrefix(v, "@")
+	}
+
+	m.config = config.Mattermost
+	m.users = users
+	m.client = &http.Client{Timeout: defaultTimeout}
+	m.loaded = true
+
+	return nil
+}
+
+func (m *Mattermost) Alert(r *reminder.Reminder) error {
+	if !m.loaded {
+		return errAlert
+	}
+
+	// sections are posted to their own channels, if any
+	for _, channel := range r.Channels() {
+		target := m.config.Channel
+		if channel != "" {
+			target = channel
+		}
+
+		if err := m.post(target, Render(r.ForChannel(channel), m.Mention)); err != nil {
+			return errors.Wrap(err, "unable to post webhook during alerting")
+		}
+	}
+
+	return m.alertDirect(r.Direct())
+}
+
+// alertDirect posts each personal section to the "@username"
+// channel of its recipient, if it is enabled.
+func (m *Mattermost) alertDirect(sections []reminder.Section) error {
+	if len(sections) == 0 {
+		return nil
+	}
+
+	if !m.config.DirectMessages {
+		alerters.SkipDirect(sections, "direct messages are disabled")
+
+		return nil
+	}
+
+	for _, s := range sections {
+		msg := Render(&reminder.Reminder{Sections: []reminder.Section{s}}, m.Mention)
+		msg.Text = s.Summary.String()
+
+		if err := m.post(fmt.Sprintf("@%s", m.resolve(*s.Recipient)), msg); err != nil {
+			return errors.Wrapf(err, "unable to send direct message to: '%s'", s.Recipient.Username)
+		}
+	}
+
+	return nil
+}
+
+func (m *Mattermost) post(channel string, msg *Message) error {
+	msg.Channel = channel
+	msg.Username = m.config.Username
+	msg.IconURL = m.config.Icon
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal message")
+	}
+
+	resp, err := m.client.Post(m.config.Webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		b, _ := ioutil.ReadAll(resp.Body)
+
+		return errors.Errorf("unexpected status: '%s': %s", resp.Status, strings.TrimSpace(string(b)))
+	}
+
+	return nil
+}
+
+// resolve returns the Mattermost username of the user by the
+// mapping, else the username of the user as it is.
+func (m *Mattermost) resolve(u reminder.User) string {
+	if v, ok := m.users[strings.ToLower(u.Username)]; ok {
+		return v
+	}
+
+	if u.Email != "" {
+		if v, ok := m.users[strings.ToLower(u.Email)]; ok {
+			return v
+		}
+	}
+
+	return u.Username
+}
+
+// Mention returns the mention of the user, i.e. "@dentrax",
+// the groups are mentioned as they are, i.e. "@here" or "@developers".
+func (m *Mattermost) Mention(u reminder.User) string {
+	if u.Group {
+		return fmt.Sprintf("@%s", strings.TrimPrefix(u.Username, "@"))
+	}
+
+	return fmt.Sprintf("@%s", m.resolve(u))
+}
+
+// markup renders the texts in Mattermost Markdown.
+var markup = alerters.Markup{
+	Link:    alerters.Markdown.Link,
+	Bold:    alerters.Markdown.Bold,
+	Heading: alerters.Markdown.Bold,
+}
+
+// Render renders the reminder like the Slack alerter, where the
+// texts are Markdown and the colors are hex codes. There is no
+// timestamp in the attachments of Mattermost.
+func Render(r *reminder.Reminder, mention func(reminder.User) string) *Message {
+	as := markup.WithMention(mention).Attachments(r)
+
+	attachments := make([]Attachment, 0, len(as))
+
+	for _, a := range as {
+		var fields []Field
+
+		for _, f := range a.Fields {
+			fields = append(fields, Field{Value: f})
+		}
+
+		attachments = append(attachments, Attachment{
+			Fallback:   a.Fallback,
+			Color:      alerters.Color(a.Severity),
+			Pretext:    a.Pretext,
+			AuthorName: a.Title,
+			AuthorLink: a.TitleLink,
+			AuthorIcon: a.Icon,
+			Text:       strings.TrimSpace(a.Text),
+			Fields:     fields,
+			Footer:     a.Footer,
+			FooterIcon: a.FooterIcon,
+		})
+	}
+
+	return &Message{
+		Attachments: attachments,
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mattermost
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/Dentrax/remind-us/pkg/config"
+	"github.com/Dentrax/remind-us/pkg/reminder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	m := &Mattermost{users: map[string]string{"dentrax": "furkan"}}
+
+	author := reminder.User{Username: "Dentrax"}
+
+	got := Render(&reminder.Reminder{
+		Sections: []reminder.Section{{
+			Title:    "baz",
+			Link:     "https://gitlab.com/foo/baz",
+			Summary:  reminder.Text{reminder.Plain("There is "), reminder.Link("1 open MR", "https://gitlab.com/foo/baz/merge_requests?state=opened")},
+			Severity: reminder.SeverityWarning,
+			Mentions: []reminder.User{{Username: "here", Group: true}},
+			Items: []reminder.Item{{
+				Title:     "foo",
+				Link:      "https://gitlab.com/foo/baz/-/merge_requests/1",
+				Details:   reminder.Text{reminder.Plain("(created "), reminder.Bold("2 days"), reminder.Plain(" ago)")},
+				Status:    reminder.StatusOK,
+				Author:    &author,
+				Reviewers: []reminder.User{author, {Username: "bar"}},
+			}},
+			Footer: "GitLab",
+		}},
+	}, m.Mention)
+
+	assert.Equal(t, &Message{Attachments: []Attachment{{
+		Fallback:   "baz: There is 1 open MR",
+		Color:      "#DAA038",
+		Pretext:    "@here",
+		AuthorName: "baz",
+		AuthorLink: "https://gitlab.com/foo/baz",
+		Text:       "There is [1 open MR](https://gitlab.com/foo/baz/merge_requests?state=opened)",
+		Fields: []Field{
+			{Value: "✓ [foo](https://gitlab.com/foo/baz/-/merge_requests/1) (created **2 days** ago) by @furkan, reviewers: @bar"},
+		},
+		Footer: "GitLab",
+	}}}, got)
+}
+
+func TestMattermost_Alert(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu  sync.Mutex
+		got []Message
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		var msg Message
+
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+
+		got = append(got, msg)
+	}))
+
+	t.Cleanup(ts.Close)
+
+	m := &Mattermost{}
+
+	assert.False(t, m.Enabled(config.AlertConfig{}))
+	assert.False(t, m.Enabled(config.AlertConfig{Mattermost: &config.MattermostAlertConfig{Enabled: "false"}}))
+	assert.True(t, m.Enabled(config.AlertConfig{Mattermost: &config.MattermostAlertConfig{}}))
+	assert.Equal(t, errAlert, m.Alert(&reminder.Reminder{}))
+	assert.Error(t, m.Load(config.AlertConfig{Mattermost: &config.MattermostAlertConfig{Webhook: "foo"}}))
+
+	assert.NoError(t, m.Load(config.AlertConfig{Mattermost: &config.MattermostAlertConfig{
+		Webhook:        ts.URL,
+		Channel:        "town-square",
+		Username:       "remind-us",
+		Users:          map[string]string{"Foo": "@foo.bar"},
+		DirectMessages: true,
+	}}))
+
+	// the sections are routed to their channels and recipients
+	assert.NoError(t, m.Alert(&reminder.Reminder{
+		Sections: []reminder.Section{
+			{Title: "baz"},
+			{Title: "security", Channel: "security", Severity: reminder.SeverityCritical},
+			{Title: "digest of foo", Summary: reminder.Text{reminder.Plain("1 MR waits for you")}, Recipient: &reminder.User{Username: "foo"}},
+		},
+	}))
+
+	assert.Len(t, got, 3)
+
+	assert.Equal(t, "town-square", got[0].Channel)
+	assert.Equal(t, "remind-us", got[0].Username)
+	assert.Equal(t, "baz", got[0].Attachments[0].AuthorName)
+
+	assert.Equal(t, "security", got[1].Channel)
+	assert.Equal(t, "#A30200", got[1].Attachments[0].Color)
+
+	assert.Equal(t, "@foo.bar", got[2].Channel)
+	assert.Equal(t, "1 MR waits for you", got[2].Text)
+}
+
+func TestMattermost_Alert_Failure(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte("Unable to parse incoming data"))
+	}))
+
+	t.Cleanup(ts.Close)
+
+	m := &Mattermost{}
+
+	assert.NoError(t, m.Load(config.AlertConfig{Mattermost: &config.MattermostAlertConfig{Webhook: ts.URL}}))
+	assert.EqualError(t, m.Alert(&reminder.Reminder{Sections: []reminder.Section{{Title: "baz"}}}), "unable to post webhook during alerting: unexpected status: '400 Bad Request': Unable to parse incoming data")
+}
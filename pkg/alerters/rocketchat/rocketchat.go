@@ -0,0 +1,291 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rocketchat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Dentrax/remind-us/pkg/alerters"
+	"github.com/Dentrax/remind-us/pkg/config"
+	"github.com/Dentrax/remind-us/pkg/reminder"
+	"github.com/pkg/errors"
+)
+
+var errAlert = errors.New("rocket.chat is not loaded")
+
+const defaultTimeout = 30 * time.Second
+
+// Message is the payload of an incoming webhook,
+// see: https://docs.rocket.chat/guides/administration/admin-panel/integrations
+type Message struct {
+	Text        string       `json:"text,omitempty"`
+	Channel     string       `json:"channel,omitempty"`
+	Alias       string       `json:"alias,omitempty"`
+	Avatar      string       `json:"avatar,omitempty"`
+	Emoji       string       `json:"emoji,omitempty"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+// Attachment is a message attachment, unlike Slack there is no
+// pretext and footer, the colors are hex codes, and the timestamp
+// is an ISO 8601 date.
+type Attachment struct {
+	Title      string  `json:"title,omitempty"`
+	TitleLink  string  `json:"title_link,omitempty"`
+	Text       string  `json:"text,omitempty"`
+	Color      string  `json:"color,omitempty"`
+	AuthorName string  `json:"author_name,omitempty"`
+	AuthorLink string  `json:"author_link,omitempty"`
+	AuthorIcon string  `json:"author_icon,omitempty"`
+	Fields     []Field `json:"fields,omitempty"`
+	Timestamp  string  `json:"ts,omitempty"`
+}
+
+type Field struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+// response is the response of the webhook, it may fail with 200 too.
+type response struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error"`
+}
+
+type RocketChat struct {
+	config *config.RocketChatAlertConfig
+
+	// users stores the Rocket.Chat usernames.
+	//
+	// map: K: lower-cased Username or Email, V: Rocket.Chat username
+	users map[string]string
+
+	client *http.Client
+	loaded bool
+}
+
+func (rc *RocketChat) Name() string {
+	return "Rocket.Chat"
+}
+
+func (rc *RocketChat) Enabled(config config.AlertConfig) bool {
+	if config.RocketChat == nil {
+		return false
+	}
+
+	if config.RocketChat.Enabled == "" {
+		return true
+	}
+
+	v, _ := strconv.ParseBool(config.RocketChat.Enabled)
+
+	return v
+}
+
+func (rc *RocketChat) Load(config config.AlertConfig) error {
+	if _, err := url.ParseRequestURI(config.RocketChat.Webhook); err != nil {
+		return errors.Wrapf(err, "incorrect 'webhook' pattern: '%s'", config.RocketChat.Webhook)
+	}
+
+	users := make(map[string]string, len(config.RocketChat.Users))
+
+	for k, v := range config.RocketChat.Users {
+		users[strings.ToLower(k)] = strings.TrimPrefix(v, "@")
+	}
+
+	rc.config = config.RocketChat
+	rc.users = users
+	rc.client = &http.Client{Timeout: defaultTimeout}
+	rc.loaded = true
+
+	return nil
+}
+
+func (rc *RocketChat) Alert(r *reminder.Reminder) error {
+	if !rc.loaded {
+		return errAlert
+	}
+
+	// sections are posted to their own channels, if any
+	for _, channel := range r.Channels() {
+		target := rc.config.Channel
+		if channel != "" {
+			target = channel
+		}
+
+		if err := rc.post(target, Render(r.ForChannel(channel), rc.Mention)); err != nil {
+			return errors.Wrap(err, "unable to post webhook during alerting")
+		}
+	}
+
+	return rc.alertDirect(r.Direct())
+}
+
+// alertDirect posts each personal section to the "@username"
+// channel of its recipient, if it is enabled.
+func (rc *RocketChat) alertDirect(sections []reminder.Section) error {
+	if len(sections) == 0 {
+		return nil
+	}
+
+	if !rc.config.DirectMessages {
+		alerters.SkipDirect(sections, "direct messages are disabled")
+
+		return nil
+	}
+
+	for _, s := range sections {
+		msg := Render(&reminder.Reminder{Sections: []reminder.Section{s}}, rc.Mention)
+
+		if err := rc.post(fmt.Sprintf("@%s", rc.resolve(*s.Recipient)), msg); err != nil {
+			return errors.Wrapf(err, "unable to send direct message to: '%s'", s.Recipient.Username)
+		}
+	}
+
+	return nil
+}
+
+func (rc *RocketChat) post(channel string, msg *Message) error {
+	msg.Channel = channel
+	msg.Alias = rc.config.Alias
+	msg.Avatar = rc.config.Avatar
+	msg.Emoji = rc.config.Emoji
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal message")
+	}
+
+	resp, err := rc.client.Post(rc.config.Webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	b, _ := ioutil.ReadAll(resp.Body)
+
+	var res response
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("unexpected status: '%s': %s", resp.Status, strings.TrimSpace(string(b)))
+	}
+
+	if err := json.Unmarshal(b, &res); err == nil && !res.Success {
+		return errors.Errorf("webhook is failed: %s", res.Error)
+	}
+
+	return nil
+}
+
+// resolve returns the Rocket.Chat username of the user by
+// the mapping, else the username of the user as it is.
+func (rc *RocketChat) resolve(u reminder.User) string {
+	if v, ok := rc.users[strings.ToLower(u.Username)]; ok {
+		return v
+	}
+
+	if u.Email != "" {
+		if v, ok := rc.users[strings.ToLower(u.Email)]; ok {
+			return v
+		}
+	}
+
+	return u.Username
+}
+
+// Mention returns the mention of the user, i.e. "@dentrax",
+// the groups are mentioned as they are, i.e. "@here" or "@all".
+func (rc *RocketChat) Mention(u reminder.User) string {
+	if u.Group {
+		return fmt.Sprintf("@%s", strings.TrimPrefix(u.Username, "@"))
+	}
+
+	return fmt.Sprintf("@%s", rc.resolve(u))
+}
+
+// markup renders the texts in Rocket.Chat Markdown, where
+// the bold ones are wrapped by a single asterisk like Slack.
+var markup = alerters.Markup{
+	Link:    alerters.Markdown.Link,
+	Bold:    bold,
+	Heading: bold,
+}
+
+func bold(text string) string {
+	return fmt.Sprintf("*%s*", text)
+}
+
+// Render renders the reminder like the Slack alerter. Since there
+// is no pretext and footer in the attachments of Rocket.Chat, the
+// mentions are rendered into the text of the message, and the
+// footers into the texts of the attachments in italics.
+func Render(r *reminder.Reminder, mention func(reminder.User) string) *Message {
+	as := markup.WithMention(mention).Attachments(r)
+
+	attachments := make([]Attachment, 0, len(as))
+
+	var mentions []string
+
+	for _, a := range as {
+		if a.Pretext != "" {
+			mentions = append(mentions, a.Pretext)
+		}
+
+		text := strings.TrimSpace(a.Text)
+
+		if a.Footer != "" {
+			text = strings.TrimSpace(fmt.Sprintf("%s\n\n_%s_", text, a.Footer))
+		}
+
+		var fields []Field
+
+		for _, f := range a.Fields {
+			fields = append(fields, Field{Value: f})
+		}
+
+		var ts string
+
+		if !a.Timestamp.IsZero() {
+			ts = a.Timestamp.UTC().Format(time.RFC3339)
+		}
+
+		attachments = append(attachments, Attachment{
+			Title:      a.Title,
+			TitleLink:  a.TitleLink,
+			AuthorIcon: a.Icon,
+			Text:       text,
+			Color:      alerters.Color(a.Severity),
+			Fields:     fields,
+			Timestamp:  ts,
+		})
+	}
+
+	return &Message{
+		Text:        strings.Join(mentions, " "),
+		Attachments: attachments,
+	}
+}
@@ -0,0 +1,173 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rocketchat
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Dentrax/remind-us/pkg/config"
+	"github.com/Dentrax/remind-us/pkg/reminder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	rc := &RocketChat{users: map[string]string{"dentrax": "furkan"}}
+
+	author := reminder.User{Username: "Dentrax"}
+
+	got := Render(&reminder.Reminder{
+		Sections: []reminder.Section{{
+			Title:     "baz",
+			Link:      "https://gitlab.com/foo/baz",
+			Summary:   reminder.Text{reminder.Plain("There is "), reminder.Link("1 open MR", "https://gitlab.com/foo/baz/merge_requests?state=opened")},
+			Severity:  reminder.SeverityWarning,
+			Mentions:  []reminder.User{{Username: "all", Group: true}},
+			Timestamp: time.Date(2020, 12, 13, 10, 0, 0, 0, time.UTC),
+			Items: []reminder.Item{{
+				Title:     "foo",
+				Link:      "https://gitlab.com/foo/baz/-/merge_requests/1",
+				Details:   reminder.Text{reminder.Plain("(created "), reminder.Bold("2 days"), reminder.Plain(" ago)")},
+				Status:    reminder.StatusOK,
+				Author:    &author,
+				Reviewers: []reminder.User{author, {Username: "bar"}},
+			}},
+			Footer: "GitLab",
+		}},
+	}, rc.Mention)
+
+	assert.Equal(t, &Message{
+		Text: "@all",
+		Attachments: []Attachment{{
+			Title:     "baz",
+			TitleLink: "https://gitlab.com/foo/baz",
+			Text:      "There is [1 open MR](https://gitlab.com/foo/baz/merge_requests?state=opened)\n\n_GitLab_",
+			Color:     "#DAA038",
+			Fields: []Field{
+				{Value: "✓ [foo](https://gitlab.com/foo/baz/-/merge_requests/1) (created *2 days* ago) by @furkan, reviewers: @bar"},
+			},
+			Timestamp: "2020-12-13T10:00:00Z",
+		}},
+	}, got)
+}
+
+func TestRocketChat_Alert(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu  sync.Mutex
+		got []Message
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		var msg Message
+
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+
+		got = append(got, msg)
+
+		_, _ = w.Write([]byte(`{"success":true}`))
+	}))
+
+	t.Cleanup(ts.Close)
+
+	rc := &RocketChat{}
+
+	assert.False(t, rc.Enabled(config.AlertConfig{}))
+	assert.False(t, rc.Enabled(config.AlertConfig{RocketChat: &config.RocketChatAlertConfig{Enabled: "false"}}))
+	assert.True(t, rc.Enabled(config.AlertConfig{RocketChat: &config.RocketChatAlertConfig{}}))
+	assert.Equal(t, errAlert, rc.Alert(&reminder.Reminder{}))
+	assert.Error(t, rc.Load(config.AlertConfig{RocketChat: &config.RocketChatAlertConfig{Webhook: "foo"}}))
+
+	assert.NoError(t, rc.Load(config.AlertConfig{RocketChat: &config.RocketChatAlertConfig{
+		Webhook:        ts.URL,
+		Channel:        "#general",
+		Alias:          "remind-us",
+		Emoji:          ":bell:",
+		Users:          map[string]string{"Foo@Example.com": "foo.bar"},
+		DirectMessages: true,
+	}}))
+
+	// the sections are routed to their channels and recipients
+	assert.NoError(t, rc.Alert(&reminder.Reminder{
+		Sections: []reminder.Section{
+			{Title: "baz"},
+			{Title: "security", Channel: "#security", Severity: reminder.SeverityCritical},
+			{Title: "digest of foo", Recipient: &reminder.User{Username: "foo", Email: "foo@example.com"}},
+		},
+	}))
+
+	assert.Len(t, got, 3)
+
+	assert.Equal(t, "#general", got[0].Channel)
+	assert.Equal(t, "remind-us", got[0].Alias)
+	assert.Equal(t, ":bell:", got[0].Emoji)
+	assert.Equal(t, "baz", got[0].Attachments[0].Title)
+
+	assert.Equal(t, "#security", got[1].Channel)
+	assert.Equal(t, "#A30200", got[1].Attachments[0].Color)
+
+	assert.Equal(t, "@foo.bar", got[2].Channel)
+	assert.Equal(t, "digest of foo", got[2].Attachments[0].Title)
+}
+
+func TestRocketChat_Alert_Failures(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/invalid" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"success":false,"error":"invalid-token"}`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"success":false,"error":"channel not found"}`))
+	}))
+
+	t.Cleanup(ts.Close)
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr string
+	}{
+		{"it should fail on 4xx", "/invalid", `unable to post webhook during alerting: unexpected status: '400 Bad Request': {"success":false,"error":"invalid-token"}`},
+		{"it should fail on unsuccessful response", "/", "unable to post webhook during alerting: webhook is failed: channel not found"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rc := &RocketChat{}
+
+			assert.NoError(t, rc.Load(config.AlertConfig{RocketChat: &config.RocketChatAlertConfig{Webhook: ts.URL + tt.path}}))
+			assert.EqualError(t, rc.Alert(&reminder.Reminder{Sections: []reminder.Section{{Title: "baz"}}}), tt.wantErr)
+		})
+	}
+}
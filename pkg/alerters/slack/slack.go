@@ -17,9 +17,16 @@ limitations under the License.
 package slack
 
 import (
+	"encoding/json"
+	"fmt"
+	"log"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/Dentrax/remind-us/pkg/alerters"
 	"github.com/Dentrax/remind-us/pkg/config"
+	"github.com/Dentrax/remind-us/pkg/reminder"
 	"github.com/pkg/errors"
 	"github.com/slack-go/slack"
 )
@@ -28,6 +35,9 @@ var errAlert = errors.New("slack is not loaded")
 
 type Slack struct {
 	config *config.SlackAlertConfig
+	users  *users
+	// client is nil if the token is not configured.
+	client *slack.Client
 	loaded bool
 }
 
@@ -50,27 +60,193 @@ func (s *Slack) Enabled(config config.AlertConfig) bool {
 }
 
 func (s *Slack) Load(config config.AlertConfig) error {
+	u := &users{
+		mapping: make(map[string]string, len(config.Slack.Users.Mapping)),
+		ttl:     defaultCacheTTL,
+	}
+
+	for k, v := range config.Slack.Users.Mapping {
+		u.mapping[strings.ToLower(k)] = v
+	}
+
+	var client *slack.Client
+
+	if config.Slack.Token != "" {
+		client = slack.New(config.Slack.Token)
+	}
+
+	if config.Slack.Users.LookupByEmail {
+		if client == nil {
+			return errors.New("slack token is required to look the users up by email")
+		}
+
+		u.client = client
+	}
+
+	if config.Slack.DirectMessages && client == nil {
+		return errors.New("slack token is required to send the direct messages")
+	}
+
+	if config.Slack.Users.CacheTTL != "" {
+		ttl, err := time.ParseDuration(config.Slack.Users.CacheTTL)
+		if err != nil {
+			return errors.Wrapf(err, "incorrect 'cacheTTL' pattern: '%s'", config.Slack.Users.CacheTTL)
+		}
+
+		u.ttl = ttl
+	}
+
 	s.config = config.Slack
+	s.users = u
+	s.client = client
 	s.loaded = true
 
 	return nil
 }
 
-func (s *Slack) Alert(message interface{}) error {
+func (s *Slack) Alert(r *reminder.Reminder) error {
 	if !s.loaded {
 		return errAlert
 	}
 
-	wh := message.(*slack.WebhookMessage) //nolint:forcetypeassert
+	// sections are posted to their own channels, if any
+	for _, channel := range r.Channels() {
+		wh := RenderWith(r.ForChannel(channel), s.users.Mention)
+
+		wh.Username = s.config.Username
+		wh.Channel = s.config.Channel
+		wh.IconEmoji = s.config.Icon
+
+		if channel != "" {
+			wh.Channel = channel
+		}
+
+		err := slack.PostWebhook(s.config.Webhook, wh)
+		if err != nil {
+			return errors.Wrap(err, "unable to post webhook during alerting")
+		}
+	}
+
+	return s.alertDirect(r.Direct())
+}
+
+// alertDirect sends each personal section to its recipient via
+// a direct message by the bot token, if it is enabled. The ones
+// whose recipients can not be resolved are skipped.
+func (s *Slack) alertDirect(sections []reminder.Section) error {
+	if len(sections) == 0 {
+		return nil
+	}
 
-	wh.Username = s.config.Username
-	wh.Channel = s.config.Channel
-	wh.IconEmoji = s.config.Icon
+	if !s.config.DirectMessages || s.client == nil {
+		alerters.SkipDirect(sections, "direct messages are disabled")
 
-	err := slack.PostWebhook(s.config.Webhook, message.(*slack.WebhookMessage))
-	if err != nil {
-		return errors.Wrap(err, "unable to post webhook during alerting")
+		return nil
+	}
+
+	for _, section := range sections {
+		id := s.users.Resolve(*section.Recipient)
+		if id == "" {
+			log.Printf("unable to resolve slack user: '%s', direct message skipped\n", section.Recipient.Username)
+
+			continue
+		}
+
+		wh := RenderWith(&reminder.Reminder{Sections: []reminder.Section{section}}, s.users.Mention)
+
+		options := []slack.MsgOption{
+			slack.MsgOptionText(section.Summary.String(), false),
+			slack.MsgOptionAttachments(wh.Attachments...),
+		}
+
+		if s.config.Username != "" {
+			options = append(options, slack.MsgOptionUsername(s.config.Username))
+		}
+
+		if s.config.Icon != "" {
+			options = append(options, slack.MsgOptionIconEmoji(s.config.Icon))
+		}
+
+		if _, _, err := s.client.PostMessage(id, options...); err != nil {
+			return errors.Wrapf(err, "unable to send direct message to: '%s'", section.Recipient.Username)
+		}
 	}
 
 	return nil
 }
+
+// Render renders the reminder into a webhook message, each
+// section becomes an attachment. Section items are rendered as
+// attachment fields, where the groups are rendered into the text
+// and the mentions of the section into the pretext.
+// Users are mentioned by their usernames.
+func Render(r *reminder.Reminder) *slack.WebhookMessage {
+	return RenderWith(r, mentionUsername)
+}
+
+// RenderWith renders the reminder like Render, mentions
+// the users by the given func.
+func RenderWith(r *reminder.Reminder, mention MentionFunc) *slack.WebhookMessage {
+	var attachments []slack.Attachment
+
+	for _, a := range markup.WithMention(mention).Attachments(r) {
+		var fields []slack.AttachmentField
+
+		for _, f := range a.Fields {
+			fields = append(fields, slack.AttachmentField{Value: f})
+		}
+
+		var ts json.Number
+
+		if !a.Timestamp.IsZero() {
+			ts = json.Number(strconv.FormatInt(a.Timestamp.Unix(), 10))
+		}
+
+		attachments = append(attachments, slack.Attachment{
+			Color:      color(a.Severity),
+			Pretext:    a.Pretext,
+			AuthorName: a.Title,
+			AuthorLink: a.TitleLink,
+			AuthorIcon: a.Icon,
+			Text:       a.Text,
+			Fields:     fields,
+			Footer:     a.Footer,
+			FooterIcon: a.FooterIcon,
+			Ts:         ts,
+		})
+	}
+
+	return &slack.WebhookMessage{
+		Attachments: attachments,
+	}
+}
+
+// markup renders the texts in Slack mrkdwn.
+var markup = alerters.Markup{
+	Link: func(text, url string) string { return fmt.Sprintf("<%s|%s>", url, text) },
+	Bold: func(text string) string { return fmt.Sprintf("*%s*", text) },
+}
+
+// FormatItem renders the item as a single line, i.e.
+// "✓ <link|title> (created 2 days ago) by <@username>, reviewers: <@username>".
+func FormatItem(item reminder.Item, mention MentionFunc) string {
+	return markup.WithMention(mention).Item(item)
+}
+
+// FormatText renders the text in Slack mrkdwn.
+func FormatText(t reminder.Text) string {
+	return markup.Text(t)
+}
+
+func color(s reminder.Severity) string {
+	switch s {
+	case reminder.SeverityWarning:
+		return "warning"
+	case reminder.SeverityCritical:
+		return "danger"
+	case reminder.SeverityOK:
+		fallthrough
+	default:
+		return "good"
+	}
+}
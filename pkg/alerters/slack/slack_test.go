@@ -0,0 +1,207 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slack
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Dentrax/remind-us/pkg/config"
+	"github.com/Dentrax/remind-us/pkg/reminder"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsers_Mention(t *testing.T) {
+	t.Parallel()
+
+	emailCache.Lock()
+	emailCache.entries = make(map[string]cachedUser)
+	emailCache.Unlock()
+
+	var lookups int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&lookups, 1)
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.FormValue("email") == "foo@bar.baz" {
+			_, _ = w.Write([]byte(`{"ok": true, "user": {"id": "U0FOO"}}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"ok": false, "error": "users_not_found"}`))
+	}))
+
+	t.Cleanup(ts.Close)
+
+	u := &users{
+		mapping: map[string]string{
+			"dentrax":      "U0DENTRAX",
+			"qux@bar.baz":  "U0QUX",
+			"mapped@b.baz": "U0MAPPED",
+			"backend":      "S0BACKEND",
+		},
+		client: slack.New("token", slack.OptionAPIURL(ts.URL+"/")),
+		ttl:    time.Hour,
+	}
+
+	tests := []struct {
+		name string
+		user reminder.User
+		want string
+	}{
+		{
+			"it should mention by username mapping",
+			reminder.User{Username: "Dentrax"},
+			"<@U0DENTRAX>",
+		},
+		{
+			"it should mention by email mapping",
+			reminder.User{Username: "qux", Email: "qux@bar.baz"},
+			"<@U0QUX>",
+		},
+		{
+			"it should mention by email lookup",
+			reminder.User{Username: "foo", Email: "foo@bar.baz"},
+			"<@U0FOO>",
+		},
+		{
+			"it should fallback to username if not found",
+			reminder.User{Username: "bar", Email: "bar@bar.baz"},
+			"<@bar>",
+		},
+		{
+			"it should mention the user group by mapping",
+			reminder.User{Username: "backend", Group: true},
+			"<!subteam^S0BACKEND>",
+		},
+		{
+			"it should mention the user group by ID",
+			reminder.User{Username: "S0FRONTEND", Group: true},
+			"<!subteam^S0FRONTEND>",
+		},
+		{
+			"it should mention the special ones",
+			reminder.User{Username: "@here", Group: true},
+			"<!here>",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, u.Mention(tt.user))
+		})
+	}
+
+	// cached ones should not be looked up again
+	assert.Equal(t, "<@U0FOO>", u.Mention(reminder.User{Username: "foo", Email: "foo@bar.baz"}))
+	assert.Equal(t, "<@bar>", u.Mention(reminder.User{Username: "bar", Email: "bar@bar.baz"}))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&lookups))
+}
+
+func TestFormatItem(t *testing.T) {
+	t.Parallel()
+
+	item := reminder.Item{
+		Status:  reminder.StatusOK,
+		Title:   "MR 1 - Title",
+		Link:    "https://gitlab.com/foo/bar/project/-/merge_requests/1",
+		Details: reminder.Text{reminder.Plain("(created "), reminder.Bold("2 days"), reminder.Plain(" ago)")},
+		Author:  &reminder.User{Username: "foo"},
+		Assignees: []reminder.User{
+			{Username: "foo"},
+			{Username: "bar"},
+		},
+		Reviewers: []reminder.User{
+			{Username: "baz"},
+			{Username: "qux"},
+		},
+	}
+
+	assert.Equal(t,
+		"✓ <https://gitlab.com/foo/bar/project/-/merge_requests/1|MR 1 - Title> (created *2 days* ago) by <@foo>, assignees: <@bar>, reviewers: <@baz> <@qux>",
+		FormatItem(item, mentionUsername),
+	)
+}
+
+func TestSlack_Alert(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		webhooks []string
+		messages []string
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if r.URL.Path == "/webhook" {
+			var wh slack.WebhookMessage
+
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&wh))
+
+			webhooks = append(webhooks, fmt.Sprintf("%s:%d", wh.Channel, len(wh.Attachments)))
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		messages = append(messages, r.FormValue("channel"))
+
+		_, _ = w.Write([]byte(`{"ok": true}`))
+	}))
+
+	t.Cleanup(ts.Close)
+
+	s := &Slack{
+		config: &config.SlackAlertConfig{
+			Webhook:        ts.URL + "/webhook",
+			Channel:        "#general",
+			DirectMessages: true,
+		},
+		users: &users{
+			mapping: map[string]string{"foo": "U0FOO"},
+		},
+		client: slack.New("token", slack.OptionAPIURL(ts.URL+"/")),
+		loaded: true,
+	}
+
+	err := s.Alert(&reminder.Reminder{
+		Sections: []reminder.Section{
+			{Title: "foo"},
+			{Title: "bar", Channel: "#escalations"},
+			{Title: "baz"},
+			{Title: "digest of foo", Recipient: &reminder.User{Username: "foo"}},
+			{Title: "digest of unknown", Recipient: &reminder.User{Username: "unknown"}},
+		},
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"#general:2", "#escalations:1"}, webhooks)
+	assert.Equal(t, []string{"U0FOO"}, messages)
+}
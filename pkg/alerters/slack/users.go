@@ -0,0 +1,152 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slack
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Dentrax/remind-us/pkg/reminder"
+	"github.com/slack-go/slack"
+)
+
+const defaultCacheTTL = 24 * time.Hour
+
+// emailCache caches the Slack user IDs looked up by email across
+// the runs in daemon mode. An empty ID means the user is not found.
+//
+// map: K: Email, V: cachedUser
+var emailCache = struct {
+	sync.Mutex
+	entries map[string]cachedUser
+}{
+	entries: make(map[string]cachedUser),
+}
+
+type cachedUser struct {
+	id      string
+	expires time.Time
+}
+
+// users resolves the Slack user IDs of the users to mention them,
+// first by the static mapping, then by looking up their emails.
+type users struct {
+	// mapping stores the Slack user IDs.
+	//
+	// map: K: lower-cased Username or Email, V: Slack user ID
+	mapping map[string]string
+
+	// client is nil if lookup by email is disabled.
+	client *slack.Client
+	ttl    time.Duration
+}
+
+// MentionFunc returns the Slack mention of the user, i.e. "<@U024BE7LH>".
+type MentionFunc func(reminder.User) string
+
+// mentionUsername mentions the users by their usernames, it is
+// used if the users can not be resolved to the Slack user IDs.
+func mentionUsername(u reminder.User) string {
+	if u.Group {
+		return mentionGroup(u.Username)
+	}
+
+	return fmt.Sprintf("<@%s>", u.Username)
+}
+
+// mentionGroup mentions the special ones, i.e. "<!here>",
+// or the user group by its ID, i.e. "<!subteam^SAZ94GDB8>".
+func mentionGroup(id string) string {
+	switch special := strings.ToLower(strings.TrimPrefix(id, "@")); special {
+	case "here", "channel", "everyone":
+		return fmt.Sprintf("<!%s>", special)
+	default:
+		return fmt.Sprintf("<!subteam^%s>", id)
+	}
+}
+
+func (u *users) Mention(user reminder.User) string {
+	if user.Group {
+		if id, ok := u.mapping[strings.ToLower(user.Username)]; ok {
+			return mentionGroup(id)
+		}
+
+		return mentionGroup(user.Username)
+	}
+
+	if id := u.Resolve(user); id != "" {
+		return fmt.Sprintf("<@%s>", id)
+	}
+
+	return mentionUsername(user)
+}
+
+// Resolve returns the Slack user ID of the user by the mapping
+// or by looking up the email, empty if it can not be resolved.
+func (u *users) Resolve(user reminder.User) string {
+	for _, k := range []string{user.Username, user.Email} {
+		if k == "" {
+			continue
+		}
+
+		if id, ok := u.mapping[strings.ToLower(k)]; ok {
+			return id
+		}
+	}
+
+	if u.client != nil && user.Email != "" {
+		return u.lookup(user.Email)
+	}
+
+	return ""
+}
+
+func (u *users) lookup(email string) string {
+	email = strings.ToLower(email)
+
+	emailCache.Lock()
+	defer emailCache.Unlock()
+
+	if c, ok := emailCache.entries[email]; ok && time.Now().Before(c.expires) {
+		return c.id
+	}
+
+	var id string
+
+	su, err := u.client.GetUserByEmail(email)
+
+	switch {
+	case err == nil:
+		id = su.ID
+	case err.Error() == "users_not_found":
+		// cache it too, not to look it up again on each reminder
+	default:
+		log.Printf("unable to look up slack user by email: '%s': %v\n", email, err)
+
+		return ""
+	}
+
+	emailCache.entries[email] = cachedUser{
+		id:      id,
+		expires: time.Now().Add(u.ttl),
+	}
+
+	return id
+}
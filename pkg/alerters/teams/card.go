@@ -0,0 +1,91 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package teams
+
+const (
+	contentTypeAdaptiveCard = "application/vnd.microsoft.card.adaptive"
+	adaptiveCardSchema      = "http://adaptivecards.io/schemas/adaptive-card.json"
+	adaptiveCardVersion     = "1.2"
+)
+
+// Message is the payload of an incoming webhook,
+// see: https://docs.microsoft.com/en-us/microsoftteams/platform/webhooks-and-connectors/how-to/connectors-using
+type Message struct {
+	Type        string       `json:"type"`
+	Attachments []Attachment `json:"attachments"`
+}
+
+type Attachment struct {
+	ContentType string `json:"contentType"`
+	Content     Card   `json:"content"`
+}
+
+// Card is an Adaptive Card, see: https://adaptivecards.io/explorer
+type Card struct {
+	Schema  string    `json:"$schema"`
+	Type    string    `json:"type"`
+	Version string    `json:"version"`
+	Body    []Element `json:"body"`
+	MSTeams *MSTeams  `json:"msteams,omitempty"`
+}
+
+// MSTeams carries the Teams specific properties of the card.
+type MSTeams struct {
+	Width    string    `json:"width,omitempty"`
+	Entities []Mention `json:"entities,omitempty"`
+}
+
+// Mention mentions the user of the "<at>Name</at>" text in the card.
+type Mention struct {
+	Type      string    `json:"type"`
+	Text      string    `json:"text"`
+	Mentioned Mentioned `json:"mentioned"`
+}
+
+type Mentioned struct {
+	// ID is either the UPN or the AAD object ID of the user.
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Element is any of the card elements used, i.e.
+// TextBlock, Image, Container, ColumnSet and Column.
+type Element struct {
+	Type      string    `json:"type"`
+	Text      string    `json:"text,omitempty"`
+	Wrap      bool      `json:"wrap,omitempty"`
+	Weight    string    `json:"weight,omitempty"`
+	Size      string    `json:"size,omitempty"`
+	Color     string    `json:"color,omitempty"`
+	IsSubtle  bool      `json:"isSubtle,omitempty"`
+	Spacing   string    `json:"spacing,omitempty"`
+	Separator bool      `json:"separator,omitempty"`
+	Style     string    `json:"style,omitempty"`
+	URL       string    `json:"url,omitempty"`
+	AltText   string    `json:"altText,omitempty"`
+	Width     string    `json:"width,omitempty"`
+	Items     []Element `json:"items,omitempty"`
+	Columns   []Element `json:"columns,omitempty"`
+}
+
+func textBlock(text string) Element {
+	return Element{
+		Type: "TextBlock",
+		Text: text,
+		Wrap: true,
+	}
+}
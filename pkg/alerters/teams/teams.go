@@ -0,0 +1,303 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package teams
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Dentrax/remind-us/pkg/alerters"
+	"github.com/Dentrax/remind-us/pkg/config"
+	"github.com/Dentrax/remind-us/pkg/reminder"
+	"github.com/pkg/errors"
+)
+
+var errAlert = errors.New("teams is not loaded")
+
+const defaultTimeout = 30 * time.Second
+
+type Teams struct {
+	config *config.TeamsAlertConfig
+
+	// channels stores the webhooks of the channels.
+	//
+	// map: K: lower-cased Channel, V: Webhook URL
+	channels map[string]string
+
+	// users stores the Teams users to mention.
+	//
+	// map: K: lower-cased Username or Email, V: UPN or AAD object ID
+	users map[string]string
+
+	client *http.Client
+	loaded bool
+}
+
+func (t *Teams) Name() string {
+	return "Teams"
+}
+
+func (t *Teams) Enabled(config config.AlertConfig) bool {
+	if config.Teams == nil {
+		return false
+	}
+
+	if config.Teams.Enabled == "" {
+		return true
+	}
+
+	v, _ := strconv.ParseBool(config.Teams.Enabled)
+
+	return v
+}
+
+func (t *Teams) Load(config config.AlertConfig) error {
+	if _, err := url.ParseRequestURI(config.Teams.Webhook); err != nil {
+		return errors.Wrapf(err, "incorrect 'webhook' pattern: '%s'", config.Teams.Webhook)
+	}
+
+	channels := make(map[string]string, len(config.Teams.Channels))
+
+	for k, v := range config.Teams.Channels {
+		if _, err := url.ParseRequestURI(v); err != nil {
+			return errors.Wrapf(err, "incorrect webhook pattern of channel: '%s'", k)
+		}
+
+		channels[strings.ToLower(k)] = v
+	}
+
+	users := make(map[string]string, len(config.Teams.Users))
+
+	for k, v := range config.Teams.Users {
+		users[strings.ToLower(k)] = v
+	}
+
+	t.config = config.Teams
+	t.channels = channels
+	t.users = users
+	t.client = &http.Client{Timeout: defaultTimeout}
+	t.loaded = true
+
+	return nil
+}
+
+func (t *Teams) Alert(r *reminder.Reminder) error {
+	if !t.loaded {
+		return errAlert
+	}
+
+	// sections are posted to the webhooks of their channels, if any
+	for _, channel := range r.Channels() {
+		webhook, ok := t.channels[strings.ToLower(channel)]
+		if !ok {
+			webhook = t.config.Webhook
+		}
+
+		if err := t.post(webhook, Render(r.ForChannel(channel), t.users)); err != nil {
+			return errors.Wrap(err, "unable to post webhook during alerting")
+		}
+	}
+
+	alerters.SkipDirect(r.Direct(), "direct messages are not supported by Teams")
+
+	return nil
+}
+
+func (t *Teams) post(webhook string, m *Message) error {
+	body, err := json.Marshal(m)
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal message")
+	}
+
+	resp, err := t.client.Post(webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		b, _ := ioutil.ReadAll(resp.Body)
+
+		return errors.Errorf("unexpected status: '%s': %s", resp.Status, strings.TrimSpace(string(b)))
+	}
+
+	return nil
+}
+
+// Render renders the reminder into an Adaptive Card, each section
+// becomes a container colored by its severity. The users are
+// mentioned by the given mapping, or by their emails if they
+// are not mapped. The others are written by their names.
+func Render(r *reminder.Reminder, users map[string]string) *Message {
+	m := newMentioner(users)
+
+	body := make([]Element, 0, len(r.Sections))
+
+	for i, s := range r.Sections {
+		var items []Element
+
+		if mentions := alerters.Markdown.WithMention(m.mention).Mentions(s.Mentions); mentions != "" {
+			items = append(items, textBlock(mentions))
+		}
+
+		items = append(items, header(s))
+
+		if len(s.Summary) > 0 {
+			items = append(items, textBlock(FormatText(s.Summary)))
+		}
+
+		for _, g := range s.Groups {
+			title := textBlock(fmt.Sprintf("**%s:**", g.Title))
+			title.Spacing = "Medium"
+
+			items = append(items, title)
+
+			for _, item := range g.Items {
+				items = append(items, itemBlock(FormatItem(item, m.mention)))
+			}
+		}
+
+		for _, item := range s.Items {
+			items = append(items, itemBlock(FormatItem(item, m.mention)))
+		}
+
+		if more := s.Overflow(); more != nil {
+			items = append(items, itemBlock(FormatText(more)))
+		}
+
+		if f := footer(s); f != "" {
+			e := textBlock(f)
+			e.Size = "Small"
+			e.IsSubtle = true
+
+			items = append(items, e)
+		}
+
+		body = append(body, Element{
+			Type:      "Container",
+			Style:     style(s.Severity),
+			Separator: i > 0,
+			Items:     items,
+		})
+	}
+
+	return &Message{
+		Type: "message",
+		Attachments: []Attachment{
+			{
+				ContentType: contentTypeAdaptiveCard,
+				Content: Card{
+					Schema:  adaptiveCardSchema,
+					Type:    "AdaptiveCard",
+					Version: adaptiveCardVersion,
+					Body:    body,
+					MSTeams: &MSTeams{
+						Width:    "Full",
+						Entities: m.entities,
+					},
+				},
+			},
+		},
+	}
+}
+
+// header renders the title of the section, with its icon if any.
+func header(s reminder.Section) Element {
+	title := s.Title
+	if s.Link != "" {
+		title = fmt.Sprintf("[%s](%s)", s.Title, s.Link)
+	}
+
+	text := textBlock(title)
+	text.Weight = "Bolder"
+
+	if s.Icon == "" {
+		return text
+	}
+
+	return Element{
+		Type: "ColumnSet",
+		Columns: []Element{
+			{
+				Type:  "Column",
+				Width: "auto",
+				Items: []Element{{Type: "Image", URL: s.Icon, AltText: s.Title, Size: "Small"}},
+			},
+			{
+				Type:  "Column",
+				Width: "stretch",
+				Items: []Element{text},
+			},
+		},
+	}
+}
+
+// footer renders the footer and the timestamp of the section,
+// the timestamp is formatted by Teams in the locale of the user.
+func footer(s reminder.Section) string {
+	parts := make([]string, 0, 2)
+
+	if s.Footer != "" {
+		parts = append(parts, s.Footer)
+	}
+
+	if !s.Timestamp.IsZero() {
+		ts := s.Timestamp.UTC().Format(time.RFC3339)
+		parts = append(parts, fmt.Sprintf("{{DATE(%s, SHORT)}} {{TIME(%s)}}", ts, ts))
+	}
+
+	return strings.Join(parts, " | ")
+}
+
+func itemBlock(text string) Element {
+	e := textBlock(text)
+	e.Spacing = "Small"
+
+	return e
+}
+
+// FormatItem renders the item as a single line, i.e.
+// "✓ [title](link) (created 2 days ago) by <at>Name</at>, reviewers: <at>Name</at>".
+func FormatItem(item reminder.Item, mention func(reminder.User) string) string {
+	return alerters.Markdown.WithMention(mention).Item(item)
+}
+
+// FormatText renders the text in the Markdown subset of Adaptive Cards.
+func FormatText(t reminder.Text) string {
+	return alerters.Markdown.Text(t)
+}
+
+func style(s reminder.Severity) string {
+	switch s {
+	case reminder.SeverityWarning:
+		return "warning"
+	case reminder.SeverityCritical:
+		return "attention"
+	case reminder.SeverityOK:
+		fallthrough
+	default:
+		return "good"
+	}
+}
@@ -0,0 +1,193 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package teams
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Dentrax/remind-us/pkg/config"
+	"github.com/Dentrax/remind-us/pkg/reminder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatItem(t *testing.T) {
+	t.Parallel()
+
+	m := newMentioner(map[string]string{"foo": "foo@example.com"})
+
+	got := FormatItem(reminder.Item{
+		Status:    reminder.StatusOK,
+		Title:     "Add Teams",
+		Link:      "https://gitlab.com/foo/bar/-/merge_requests/1",
+		Details:   reminder.Text{reminder.Plain("(created "), reminder.Bold("3 days"), reminder.Plain(" ago)")},
+		Author:    &reminder.User{Username: "foo", Name: "Foo"},
+		Assignees: []reminder.User{{Username: "foo", Name: "Foo"}},
+		Reviewers: []reminder.User{{Username: "bar", Name: "Bar", Email: "bar@example.com"}, {Username: "baz"}},
+	}, m.mention)
+
+	assert.Equal(t, "✓ [Add Teams](https://gitlab.com/foo/bar/-/merge_requests/1) (created **3 days** ago) by <at>Foo</at>, reviewers: <at>Bar</at> baz", got)
+	assert.Equal(t, []Mention{
+		{Type: "mention", Text: "<at>Foo</at>", Mentioned: Mentioned{ID: "foo@example.com", Name: "Foo"}},
+		{Type: "mention", Text: "<at>Bar</at>", Mentioned: Mentioned{ID: "bar@example.com", Name: "Bar"}},
+	}, m.entities)
+}
+
+func TestMentioner_SameName(t *testing.T) {
+	t.Parallel()
+
+	m := newMentioner(map[string]string{"foo": "foo@example.com", "bar": "bar@example.com"})
+
+	got := []string{
+		m.mention(reminder.User{Username: "foo", Name: "Furkan"}),
+		m.mention(reminder.User{Username: "bar", Name: "Furkan"}),
+		m.mention(reminder.User{Username: "foo", Name: "Furkan"}),
+		m.mention(reminder.User{Username: "baz", Name: "Furkan", Email: "foo@example.com"}),
+	}
+
+	assert.Equal(t, []string{"<at>Furkan</at>", "<at>Furkan (bar)</at>", "<at>Furkan</at>", "<at>Furkan</at>"}, got)
+	assert.Equal(t, []Mention{
+		{Type: "mention", Text: "<at>Furkan</at>", Mentioned: Mentioned{ID: "foo@example.com", Name: "Furkan"}},
+		{Type: "mention", Text: "<at>Furkan (bar)</at>", Mentioned: Mentioned{ID: "bar@example.com", Name: "Furkan"}},
+	}, m.entities)
+}
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	got := Render(&reminder.Reminder{
+		Sections: []reminder.Section{
+			{
+				Title:     "baz",
+				Link:      "https://gitlab.com/foo/baz",
+				Icon:      "https://gitlab.com/avatar.png",
+				Summary:   reminder.Text{reminder.Plain("There is "), reminder.Link("1 open MR", "https://gitlab.com/foo/baz/merge_requests?state=opened")},
+				Groups:    []reminder.Group{{Title: "1 MR is awaiting review", Items: []reminder.Item{{Title: "foo", Link: "https://gitlab.com/foo/baz/-/merge_requests/1"}}}},
+				Footer:    "foo",
+				Severity:  reminder.SeverityCritical,
+				Timestamp: time.Date(2021, time.March, 24, 20, 0, 0, 0, time.UTC),
+				Mentions:  []reminder.User{{Username: "here", Group: true}},
+				More:      2,
+			},
+			{
+				Title: "Hacker News",
+				Items: []reminder.Item{{Title: "bar", Link: "https://news.ycombinator.com/item?id=1"}},
+			},
+		},
+	}, nil)
+
+	assert.Equal(t, "message", got.Type)
+	assert.Len(t, got.Attachments, 1)
+	assert.Equal(t, contentTypeAdaptiveCard, got.Attachments[0].ContentType)
+
+	body := got.Attachments[0].Content.Body
+	assert.Len(t, body, 2)
+
+	texts := func(e Element) []string {
+		var result []string
+
+		for _, i := range e.Items {
+			result = append(result, i.Text)
+		}
+
+		return result
+	}
+
+	assert.Equal(t, "attention", body[0].Style)
+	assert.False(t, body[0].Separator)
+	assert.Equal(t, "ColumnSet", body[0].Items[1].Type)
+	assert.Equal(t, []string{
+		"@here",
+		"",
+		"There is [1 open MR](https://gitlab.com/foo/baz/merge_requests?state=opened)",
+		"**1 MR is awaiting review:**",
+		"• [foo](https://gitlab.com/foo/baz/-/merge_requests/1) ",
+		"[…and 2 more](https://gitlab.com/foo/baz)",
+		"foo | {{DATE(2021-03-24T20:00:00Z, SHORT)}} {{TIME(2021-03-24T20:00:00Z)}}",
+	}, texts(body[0]))
+
+	assert.Equal(t, "good", body[1].Style)
+	assert.True(t, body[1].Separator)
+	assert.Equal(t, []string{
+		"Hacker News",
+		"• [bar](https://news.ycombinator.com/item?id=1) ",
+	}, texts(body[1]))
+}
+
+func TestTeams_Alert(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu    sync.Mutex
+		posts []string
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		var m Message
+
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&m))
+
+		posts = append(posts, r.URL.Path+":"+m.Attachments[0].Content.Body[0].Items[0].Text)
+
+		if r.URL.Path == "/failing" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+
+	t.Cleanup(ts.Close)
+
+	tm := &Teams{}
+
+	assert.True(t, tm.Enabled(config.AlertConfig{Teams: &config.TeamsAlertConfig{}}))
+	assert.False(t, tm.Enabled(config.AlertConfig{Teams: &config.TeamsAlertConfig{Enabled: "false"}}))
+
+	err := tm.Alert(&reminder.Reminder{})
+	assert.Equal(t, errAlert, err)
+
+	err = tm.Load(config.AlertConfig{Teams: &config.TeamsAlertConfig{
+		Webhook:  ts.URL + "/general",
+		Channels: map[string]string{"#Escalations": ts.URL + "/escalations"},
+	}})
+	assert.NoError(t, err)
+
+	err = tm.Alert(&reminder.Reminder{
+		Sections: []reminder.Section{
+			{Title: "foo"},
+			{Title: "bar", Channel: "#escalations"},
+			{Title: "baz", Channel: "#unknown"},
+			{Title: "digest of foo", Recipient: &reminder.User{Username: "foo"}},
+		},
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"/general:foo", "/escalations:bar", "/general:baz"}, posts)
+
+	tm.config.Webhook = ts.URL + "/failing"
+
+	err = tm.Alert(&reminder.Reminder{Sections: []reminder.Section{{Title: "foo"}}})
+	assert.Error(t, err)
+
+	err = tm.Load(config.AlertConfig{Teams: &config.TeamsAlertConfig{Webhook: "foo"}})
+	assert.EqualError(t, err, `incorrect 'webhook' pattern: 'foo': parse "foo": invalid URI for request`)
+}
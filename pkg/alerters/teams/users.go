@@ -0,0 +1,117 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package teams
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Dentrax/remind-us/pkg/reminder"
+)
+
+// mentioner mentions the users in a card, collecting
+// the mention entities the card must carry.
+type mentioner struct {
+	// users stores the Teams users to mention.
+	//
+	// map: K: lower-cased Username or Email, V: UPN or AAD object ID
+	users map[string]string
+
+	entities []Mention
+
+	// seen stores the texts of the mentioned users, each needs a single entity.
+	//
+	// map: K: UPN or AAD object ID, V: mention text
+	seen map[string]string
+
+	// texts stores the users of the mention texts, the users
+	// with the same name need different texts to be told apart.
+	//
+	// map: K: mention text, V: UPN or AAD object ID
+	texts map[string]string
+}
+
+func newMentioner(users map[string]string) *mentioner {
+	return &mentioner{
+		users: users,
+		seen:  make(map[string]string),
+		texts: make(map[string]string),
+	}
+}
+
+// mention returns the mention of the user, i.e. "<at>Furkan Türkal</at>",
+// or the name of the user if it can not be resolved. The groups can not
+// be mentioned by webhooks, they are written as they are, i.e. "@here".
+func (m *mentioner) mention(u reminder.User) string {
+	name := u.Name
+	if name == "" {
+		name = u.Username
+	}
+
+	if u.Group {
+		return fmt.Sprintf("@%s", strings.TrimPrefix(u.Username, "@"))
+	}
+
+	id := m.resolve(u)
+	if id == "" {
+		return name
+	}
+
+	if text, ok := m.seen[id]; ok {
+		return text
+	}
+
+	// the users with the same name are told apart by their usernames,
+	// or by their IDs, i.e. "<at>Furkan Türkal (dentrax)</at>"
+	text := fmt.Sprintf("<at>%s</at>", name)
+
+	for _, suffix := range []string{u.Username, id} {
+		if other, ok := m.texts[text]; !ok || other == id {
+			break
+		}
+
+		text = fmt.Sprintf("<at>%s (%s)</at>", name, suffix)
+	}
+
+	m.seen[id] = text
+	m.texts[text] = id
+	m.entities = append(m.entities, Mention{
+		Type:      "mention",
+		Text:      text,
+		Mentioned: Mentioned{ID: id, Name: name},
+	})
+
+	return text
+}
+
+// resolve returns the UPN or the AAD object ID of the
+// user by the mapping, else the email of the user.
+func (m *mentioner) resolve(u reminder.User) string {
+	if id, ok := m.users[strings.ToLower(u.Username)]; ok && u.Username != "" {
+		return id
+	}
+
+	if u.Email == "" {
+		return ""
+	}
+
+	if id, ok := m.users[strings.ToLower(u.Email)]; ok {
+		return id
+	}
+
+	return u.Email
+}
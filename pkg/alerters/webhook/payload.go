@@ -0,0 +1,180 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"time"
+
+	"github.com/Dentrax/remind-us/pkg/alerters"
+	"github.com/Dentrax/remind-us/pkg/reminder"
+)
+
+// Payload is the default body of the webhooks, {{json .Payload}}. Unlike
+// the reminder, its fields are stable for the consumers, the texts are
+// rendered in Markdown and the empty fields are omitted.
+type Payload struct {
+	Source   string           `json:"source"`
+	Channel  string           `json:"channel,omitempty"`
+	Sections []PayloadSection `json:"sections"`
+}
+
+type PayloadSection struct {
+	Title   string `json:"title"`
+	Link    string `json:"link,omitempty"`
+	Summary string `json:"summary,omitempty"`
+	// Severity is "ok", "warning" or "critical".
+	Severity string         `json:"severity"`
+	Mentions []PayloadUser  `json:"mentions,omitempty"`
+	Groups   []PayloadGroup `json:"groups,omitempty"`
+	Items    []PayloadItem  `json:"items,omitempty"`
+	// More is the number of the items left out, i.e. by the maxItems.
+	More      int        `json:"more,omitempty"`
+	Footer    string     `json:"footer,omitempty"`
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+}
+
+type PayloadGroup struct {
+	Title string        `json:"title"`
+	Items []PayloadItem `json:"items"`
+}
+
+type PayloadItem struct {
+	// Status is "ok" or "failed" if the item has any, i.e. the pipeline of an MR.
+	Status    string        `json:"status,omitempty"`
+	Title     string        `json:"title"`
+	Link      string        `json:"link,omitempty"`
+	Details   string        `json:"details,omitempty"`
+	Time      *time.Time    `json:"time,omitempty"`
+	Author    *PayloadUser  `json:"author,omitempty"`
+	Assignees []PayloadUser `json:"assignees,omitempty"`
+	Reviewers []PayloadUser `json:"reviewers,omitempty"`
+}
+
+type PayloadUser struct {
+	Username string `json:"username"`
+	Name     string `json:"name,omitempty"`
+	// Group is true for the groups and the special mentions, i.e. "here".
+	Group bool `json:"group,omitempty"`
+}
+
+// Payload returns the default body of the data.
+func (d Data) Payload() Payload {
+	sections := make([]PayloadSection, 0, len(d.Sections))
+
+	for _, s := range d.Sections {
+		p := PayloadSection{
+			Title:    s.Title,
+			Link:     s.Link,
+			Summary:  alerters.Markdown.Text(s.Summary),
+			Severity: severity(s.Severity),
+			Mentions: payloadUsers(s.Mentions),
+			Items:    payloadItems(s.Items),
+			More:     s.More,
+			Footer:   s.Footer,
+		}
+
+		for _, g := range s.Groups {
+			p.Groups = append(p.Groups, PayloadGroup{Title: g.Title, Items: payloadItems(g.Items)})
+		}
+
+		if !s.Timestamp.IsZero() {
+			ts := s.Timestamp.UTC()
+			p.Timestamp = &ts
+		}
+
+		sections = append(sections, p)
+	}
+
+	return Payload{
+		Source:   d.Source,
+		Channel:  d.Channel,
+		Sections: sections,
+	}
+}
+
+func payloadItems(items []reminder.Item) []PayloadItem {
+	if len(items) == 0 {
+		return nil
+	}
+
+	result := make([]PayloadItem, 0, len(items))
+
+	for _, i := range items {
+		p := PayloadItem{
+			Status:    status(i.Status),
+			Title:     i.Title,
+			Link:      i.Link,
+			Details:   alerters.Markdown.Text(i.Details),
+			Time:      i.Time,
+			Assignees: payloadUsers(i.Assignees),
+			Reviewers: payloadUsers(i.Reviewers),
+		}
+
+		if i.Author != nil {
+			author := payloadUser(*i.Author)
+			p.Author = &author
+		}
+
+		result = append(result, p)
+	}
+
+	return result
+}
+
+func payloadUsers(users []reminder.User) []PayloadUser {
+	if len(users) == 0 {
+		return nil
+	}
+
+	result := make([]PayloadUser, 0, len(users))
+
+	for _, u := range users {
+		result = append(result, payloadUser(u))
+	}
+
+	return result
+}
+
+func payloadUser(u reminder.User) PayloadUser {
+	return PayloadUser{Username: u.Username, Name: u.Name, Group: u.Group}
+}
+
+func severity(s reminder.Severity) string {
+	switch s {
+	case reminder.SeverityWarning:
+		return "warning"
+	case reminder.SeverityCritical:
+		return "critical"
+	case reminder.SeverityOK:
+		fallthrough
+	default:
+		return "ok"
+	}
+}
+
+func status(s reminder.Status) string {
+	switch s {
+	case reminder.StatusOK:
+		return "ok"
+	case reminder.StatusFailed:
+		return "failed"
+	case reminder.StatusNone:
+		fallthrough
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"text/template"
+
+	"github.com/Dentrax/remind-us/pkg/alerters"
+	"github.com/Dentrax/remind-us/pkg/reminder"
+)
+
+// Data is the data of the templates, the sections of a single channel.
+type Data struct {
+	// Source is the name of the integration, i.e. "GitLab".
+	Source string
+	// Channel is the channel of the sections, empty for the default one.
+	Channel  string
+	Sections []reminder.Section
+}
+
+// funcs are the functions of the templates, in addition to the builtin ones:
+//
+//	json:     encodes the value in JSON, i.e. {"text": {{json .Title}}}
+//	markdown: renders the text in Markdown, i.e. "[1 open MR](link)"
+//	join:     joins the strings by the separator
+//	lower, upper: changes the case of the string
+//
+// The texts are rendered in plaintext by default, i.e. {{.Summary}}.
+var funcs = template.FuncMap{
+	"json":     toJSON,
+	"markdown": alerters.Markdown.Text,
+	"join":     func(sep string, s []string) string { return strings.Join(s, sep) },
+	"lower":    strings.ToLower,
+	"upper":    strings.ToUpper,
+}
+
+func execute(tmpl *template.Template, data Data) (string, error) {
+	var b bytes.Buffer
+
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+func toJSON(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
@@ -0,0 +1,335 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Dentrax/remind-us/pkg/alerters"
+	"github.com/Dentrax/remind-us/pkg/config"
+	"github.com/Dentrax/remind-us/pkg/reminder"
+	"github.com/pkg/errors"
+)
+
+var errAlert = errors.New("webhook is not loaded")
+
+const (
+	defaultMethod          = http.MethodPost
+	defaultBody            = "{{json .Payload}}"
+	defaultSignatureHeader = "X-Remind-Us-Signature"
+	defaultTimeout         = 30 * time.Second
+	defaultWait            = time.Second
+)
+
+type Webhook struct {
+	targets []*target
+	loaded  bool
+}
+
+// target is a configured webhook, the templates
+// are parsed once on Load.
+type target struct {
+	name    string
+	config  config.WebhookAlertConfig
+	url     *template.Template
+	method  *template.Template
+	body    *template.Template
+	headers map[string]*template.Template
+	retries int
+	// wait is multiplied by the attempt before retrying.
+	wait   time.Duration
+	client *http.Client
+}
+
+// request is a rendered request of a target.
+type request struct {
+	method  string
+	url     string
+	headers http.Header
+	body    []byte
+}
+
+func (w *Webhook) Name() string {
+	return "Webhook"
+}
+
+// Enabled returns true if any of the webhooks is enabled.
+func (w *Webhook) Enabled(config config.AlertConfig) bool {
+	for _, c := range config.Webhooks {
+		if enabled(c) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func enabled(c config.WebhookAlertConfig) bool {
+	if c.Enabled == "" {
+		return true
+	}
+
+	v, _ := strconv.ParseBool(c.Enabled)
+
+	return v
+}
+
+func (w *Webhook) Load(config config.AlertConfig) error {
+	targets := make([]*target, 0, len(config.Webhooks))
+
+	for i, c := range config.Webhooks {
+		if !enabled(c) {
+			continue
+		}
+
+		name := c.Name
+		if name == "" {
+			name = strconv.Itoa(i)
+		}
+
+		t, err := newTarget(name, c)
+		if err != nil {
+			return errors.Wrapf(err, "incorrect webhook: '%s'", name)
+		}
+
+		targets = append(targets, t)
+	}
+
+	w.targets = targets
+	w.loaded = true
+
+	return nil
+}
+
+func newTarget(name string, c config.WebhookAlertConfig) (*target, error) {
+	t := &target{
+		name:    name,
+		config:  c,
+		headers: make(map[string]*template.Template, len(c.Headers)),
+		retries: c.Retries,
+		wait:    defaultWait,
+	}
+
+	if c.URL == "" {
+		return nil, errors.New("url is required")
+	}
+
+	method := c.Method
+	if method == "" {
+		method = defaultMethod
+	}
+
+	body := c.Body
+	if body == "" {
+		body = defaultBody
+	}
+
+	var err error
+
+	if t.url, err = parse("url", c.URL); err != nil {
+		return nil, err
+	}
+
+	if t.method, err = parse("method", method); err != nil {
+		return nil, err
+	}
+
+	if t.body, err = parse("body", body); err != nil {
+		return nil, err
+	}
+
+	for k, v := range c.Headers {
+		if t.headers[k], err = parse("header", v); err != nil {
+			return nil, err
+		}
+	}
+
+	timeout := defaultTimeout
+
+	if c.Timeout != "" {
+		if timeout, err = time.ParseDuration(c.Timeout); err != nil {
+			return nil, errors.Wrapf(err, "incorrect 'timeout' pattern: '%s'", c.Timeout)
+		}
+	}
+
+	if t.retries < 0 {
+		return nil, errors.Errorf("incorrect 'retries': '%d'", t.retries)
+	}
+
+	t.client = &http.Client{Timeout: timeout}
+
+	return t, nil
+}
+
+func parse(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, errors.Wrapf(err, "incorrect '%s' template: '%s'", name, text)
+	}
+
+	return tmpl, nil
+}
+
+func (w *Webhook) Alert(r *reminder.Reminder) error {
+	if !w.loaded {
+		return errAlert
+	}
+
+	for _, t := range w.targets {
+		// each channel is posted on its own, so the templates can route them
+		for _, channel := range r.Channels() {
+			req, err := t.render(Data{
+				Source:   r.Source,
+				Channel:  channel,
+				Sections: r.ForChannel(channel).Sections,
+			})
+			if err != nil {
+				return errors.Wrapf(err, "unable to render webhook: '%s'", t.name)
+			}
+
+			if err := t.send(req); err != nil {
+				return errors.Wrapf(err, "unable to post webhook: '%s'", t.name)
+			}
+		}
+	}
+
+	alerters.SkipDirect(r.Direct(), "direct messages are not supported by webhooks")
+
+	return nil
+}
+
+// render renders the request of the data, and signs the body if the secret is set.
+func (t *target) render(data Data) (*request, error) {
+	u, err := execute(t.url, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := url.ParseRequestURI(u); err != nil {
+		return nil, errors.Wrapf(err, "incorrect URL: '%s'", u)
+	}
+
+	method, err := execute(t.method, data)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := execute(t.body, data)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &request{
+		method:  strings.ToUpper(strings.TrimSpace(method)),
+		url:     u,
+		headers: make(http.Header, len(t.headers)+1),
+		body:    []byte(body),
+	}
+
+	for k, tmpl := range t.headers {
+		v, err := execute(tmpl, data)
+		if err != nil {
+			return nil, err
+		}
+
+		req.headers.Set(k, v)
+	}
+
+	if req.headers.Get("Content-Type") == "" {
+		req.headers.Set("Content-Type", "application/json")
+	}
+
+	if t.config.Secret != "" {
+		header := t.config.SignatureHeader
+		if header == "" {
+			header = defaultSignatureHeader
+		}
+
+		req.headers.Set(header, sign(t.config.Secret, req.body))
+	}
+
+	return req, nil
+}
+
+// send sends the request, the attempts failing by
+// a network error or 5xx are retried.
+func (t *target) send(req *request) error {
+	var err error
+
+	for attempt := 0; attempt <= t.retries; attempt++ {
+		if attempt > 0 {
+			log.Printf("retrying webhook: '%s' (%d/%d): %v\n", t.name, attempt, t.retries, err)
+
+			time.Sleep(t.wait * time.Duration(attempt))
+		}
+
+		var retry bool
+
+		retry, err = t.sendOnce(req)
+		if err == nil || !retry {
+			return err
+		}
+	}
+
+	return err
+}
+
+// sendOnce sends the request once, it returns
+// true if the failed attempt can be retried.
+func (t *target) sendOnce(req *request) (bool, error) {
+	r, err := http.NewRequest(req.method, req.url, bytes.NewReader(req.body))
+	if err != nil {
+		return false, err
+	}
+
+	r.Header = req.headers.Clone()
+
+	resp, err := t.client.Do(r)
+	if err != nil {
+		return true, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		b, _ := ioutil.ReadAll(resp.Body)
+
+		return resp.StatusCode >= 500, errors.Errorf("unexpected status: '%s': %s", resp.Status, strings.TrimSpace(string(b)))
+	}
+
+	return false, nil
+}
+
+// sign returns the HMAC-SHA256 of the body, i.e. "sha256=<hex>".
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+
+	return fmt.Sprintf("sha256=%s", hex.EncodeToString(mac.Sum(nil)))
+}
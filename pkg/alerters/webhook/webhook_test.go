@@ -0,0 +1,257 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Dentrax/remind-us/pkg/config"
+	"github.com/Dentrax/remind-us/pkg/reminder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhook_Alert(t *testing.T) {
+	t.Parallel()
+
+	type received struct {
+		method    string
+		path      string
+		header    http.Header
+		body      string
+		signature string
+	}
+
+	var (
+		mu    sync.Mutex
+		got   []received
+		calls int
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		calls++
+
+		// the first attempt fails
+		if calls == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		b, _ := ioutil.ReadAll(r.Body)
+
+		got = append(got, received{
+			method:    r.Method,
+			path:      r.URL.RequestURI(),
+			header:    r.Header,
+			body:      string(b),
+			signature: r.Header.Get("X-Signature"),
+		})
+	}))
+
+	t.Cleanup(ts.Close)
+
+	w := &Webhook{}
+
+	assert.False(t, w.Enabled(config.AlertConfig{}))
+	assert.False(t, w.Enabled(config.AlertConfig{Webhooks: []config.WebhookAlertConfig{{Enabled: "false"}}}))
+	assert.True(t, w.Enabled(config.AlertConfig{Webhooks: []config.WebhookAlertConfig{{Enabled: "false"}, {}}}))
+	assert.Equal(t, errAlert, w.Alert(&reminder.Reminder{}))
+
+	err := w.Load(config.AlertConfig{Webhooks: []config.WebhookAlertConfig{
+		{
+			Name:    "incident-bot",
+			URL:     ts.URL + `/hooks/{{if .Channel}}{{.Channel | urlquery}}{{else}}general{{end}}`,
+			Method:  `{{if .Channel}}put{{else}}post{{end}}`,
+			Headers: map[string]string{"X-Source": "{{.Source | lower}}"},
+			Body: `{"text": {{json (printf "%d section(s)" (len .Sections))}}, "items": [
+				{{- range $i, $s := .Sections}}{{range $j, $item := $s.Items}}{{if or $i $j}}, {{end}}{{json $item.Title}}{{end}}{{end -}}
+			], "summary": {{json (markdown (index .Sections 0).Summary)}}}`,
+			Secret:          "s3cr3t",
+			SignatureHeader: "X-Signature",
+			Retries:         1,
+		},
+		{
+			Enabled: "false",
+			URL:     "http://example.com/disabled",
+		},
+	}})
+	assert.NoError(t, err)
+	assert.Len(t, w.targets, 1)
+
+	w.targets[0].wait = 0
+
+	// a request per channel, the direct sections are skipped
+	assert.NoError(t, w.Alert(&reminder.Reminder{
+		Source: "GitLab",
+		Sections: []reminder.Section{
+			{
+				Title:   "baz",
+				Summary: reminder.Text{reminder.Plain("There is "), reminder.Link("1 open MR", "https://gitlab.com/foo/baz/merge_requests?state=opened")},
+				Items:   []reminder.Item{{Title: "foo \"bar\"", Link: "https://gitlab.com/foo/baz/-/merge_requests/1"}},
+			},
+			{Title: "security", Channel: "#security"},
+			{Title: "digest of foo", Recipient: &reminder.User{Username: "foo"}},
+		},
+	}))
+
+	assert.Equal(t, 3, calls)
+	assert.Len(t, got, 2)
+
+	assert.Equal(t, http.MethodPost, got[0].method)
+	assert.Equal(t, "/hooks/general", got[0].path)
+	assert.Equal(t, "gitlab", got[0].header.Get("X-Source"))
+	assert.Equal(t, "application/json", got[0].header.Get("Content-Type"))
+	assert.Equal(t, sign("s3cr3t", []byte(got[0].body)), got[0].signature)
+
+	var body struct {
+		Text    string   `json:"text"`
+		Items   []string `json:"items"`
+		Summary string   `json:"summary"`
+	}
+
+	assert.NoError(t, json.Unmarshal([]byte(got[0].body), &body))
+	assert.Equal(t, "1 section(s)", body.Text)
+	assert.Equal(t, []string{`foo "bar"`}, body.Items)
+	assert.Equal(t, "There is [1 open MR](https://gitlab.com/foo/baz/merge_requests?state=opened)", body.Summary)
+
+	assert.Equal(t, http.MethodPut, got[1].method)
+	assert.Equal(t, "/hooks/%23security", got[1].path)
+}
+
+func TestWebhook_Alert_DefaultBody(t *testing.T) {
+	t.Parallel()
+
+	var body string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "", r.Header.Get(defaultSignatureHeader))
+
+		b, _ := ioutil.ReadAll(r.Body)
+		body = string(b)
+	}))
+
+	t.Cleanup(ts.Close)
+
+	created := time.Date(2021, time.March, 24, 20, 0, 0, 0, time.UTC)
+
+	w := &Webhook{}
+
+	assert.NoError(t, w.Load(config.AlertConfig{Webhooks: []config.WebhookAlertConfig{{URL: ts.URL}}}))
+	assert.NoError(t, w.Alert(&reminder.Reminder{
+		Source: "RSS",
+		Sections: []reminder.Section{
+			{
+				Title:     "foo",
+				Link:      "https://example.com",
+				Summary:   reminder.Text{reminder.Bold("1"), reminder.Plain(" new post")},
+				Severity:  reminder.SeverityWarning,
+				Mentions:  []reminder.User{{Username: "here", Group: true}},
+				Groups:    []reminder.Group{{Title: "bar", Items: []reminder.Item{{Title: "baz", Key: "https://example.com\x00baz"}}}},
+				Items:     []reminder.Item{{Status: reminder.StatusFailed, Title: "qux", Link: "https://example.com/qux", Time: &created, Author: &reminder.User{Username: "foo", Name: "Foo", Email: "foo@example.com"}}},
+				More:      2,
+				Timestamp: created,
+			},
+		},
+	}))
+
+	assert.Equal(t, `{"source":"RSS","sections":[{"title":"foo","link":"https://example.com","summary":"**1** new post","severity":"warning",`+
+		`"mentions":[{"username":"here","group":true}],"groups":[{"title":"bar","items":[{"title":"baz"}]}],`+
+		`"items":[{"status":"failed","title":"qux","link":"https://example.com/qux","time":"2021-03-24T20:00:00Z","author":{"username":"foo","name":"Foo"}}],`+
+		`"more":2,"timestamp":"2021-03-24T20:00:00Z"}]}`, body)
+}
+
+func TestWebhook_Alert_Failures(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu    sync.Mutex
+		calls int
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		calls++
+
+		if r.URL.Path == "/bad-request" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte("invalid payload"))
+
+			return
+		}
+
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	t.Cleanup(ts.Close)
+
+	tests := []struct {
+		name      string
+		path      string
+		wantCalls int
+		wantErr   string
+	}{
+		{"it should not retry on 4xx", "/bad-request", 1, "unable to post webhook: 'foo': unexpected status: '400 Bad Request': invalid payload"},
+		{"it should give up after the retries", "/unavailable", 3, "unable to post webhook: 'foo': unexpected status: '503 Service Unavailable': "},
+	}
+
+	for _, tt := range tests {
+		mu.Lock()
+		calls = 0
+		mu.Unlock()
+
+		w := &Webhook{}
+
+		assert.NoError(t, w.Load(config.AlertConfig{Webhooks: []config.WebhookAlertConfig{{Name: "foo", URL: ts.URL + tt.path, Retries: 2}}}))
+
+		w.targets[0].wait = 0
+
+		assert.EqualError(t, w.Alert(&reminder.Reminder{Sections: []reminder.Section{{Title: "foo"}}}), tt.wantErr, tt.name)
+		assert.Equal(t, tt.wantCalls, calls, tt.name)
+	}
+}
+
+func TestWebhook_Load_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		config  config.WebhookAlertConfig
+		wantErr string
+	}{
+		{"no url", config.WebhookAlertConfig{}, "incorrect webhook: '0': url is required"},
+		{"body", config.WebhookAlertConfig{Name: "foo", URL: "http://example.com", Body: "{{json .Source"}, "incorrect webhook: 'foo': incorrect 'body' template: '{{json .Source': template: body:1: unclosed action"},
+		{"timeout", config.WebhookAlertConfig{Name: "foo", URL: "http://example.com", Timeout: "1"}, "incorrect webhook: 'foo': incorrect 'timeout' pattern: '1': time: missing unit in duration \"1\""},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.EqualError(t, (&Webhook{}).Load(config.AlertConfig{Webhooks: []config.WebhookAlertConfig{tt.config}}), tt.wantErr)
+		})
+	}
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package config
 
 import (
+	"path/filepath"
+
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
 )
@@ -24,6 +26,7 @@ import (
 type Config struct {
 	Integrations Integrations `yaml:"integrations"`
 	Alerts       AlertConfig  `yaml:"alert"`
+	State        *StateConfig `yaml:"state"`
 }
 
 type Integrations struct {
@@ -32,22 +35,149 @@ type Integrations struct {
 }
 
 type GitLabIntegrationConfig struct {
-	Enabled string                  `yaml:"enabled"`
-	Type    string                  `yaml:"type"`
-	BaseURL string                  `yaml:"baseURL"`
-	Token   string                  `yaml:"token"`
-	Listen  IntegrationListenConfig `yaml:"listen"`
+	Enabled  string                  `yaml:"enabled"`
+	Type     string                  `yaml:"type"`
+	Schedule string                  `yaml:"schedule"`
+	BaseURL  string                  `yaml:"baseURL"`
+	Token    string                  `yaml:"token"`
+	Listen   IntegrationListenConfig `yaml:"listen"`
+	// Workers is the number of the projects scanned concurrently. Defaults to 4.
+	Workers int `yaml:"workers"`
+	// ResolveEmails fetches the public emails of the users to
+	// be mentioned, so alerters can look them up by email.
+	ResolveEmails bool `yaml:"resolveEmails"`
+	// Mode is either "project", "digest" or "both". The project mode
+	// groups the MRs by project, where the digest mode regroups them
+	// by the reviewers, assignees and authors as personal digests.
+	// Defaults to "project".
+	Mode          string                   `yaml:"mode"`
+	Staleness     StalenessConfig          `yaml:"staleness"`
+	Order         OrderConfig              `yaml:"order"`
+	MergeRequests MergeRequestFilterConfig `yaml:"mergeRequests"`
+	// MaxItems is the maximum number of the items in a message, and
+	// MaxItemsPerProject in each section of a project, i.e. the MRs.
+	// The rest are summarized. 0 means no limit.
+	MaxItems           int `yaml:"maxItems"`
+	MaxItemsPerProject int `yaml:"maxItemsPerProject"`
+}
+
+// MergeRequestFilterConfig filters the open MRs before they are counted.
+type MergeRequestFilterConfig struct {
+	// SkipDrafts skips the Draft and the WIP MRs.
+	SkipDrafts bool              `yaml:"skipDrafts"`
+	Labels     LabelFilterConfig `yaml:"labels"`
+	// TargetBranches are the glob patterns that the target
+	// branch must match one of, if any.
+	TargetBranches []string `yaml:"targetBranches"`
+	// ExcludeAuthors are the usernames, i.e. "renovate-bot".
+	ExcludeAuthors []string `yaml:"excludeAuthors"`
+}
+
+// LabelFilterConfig filters by the labels, an MR must have one of
+// the included labels, if any, and none of the excluded ones.
+type LabelFilterConfig struct {
+	Include []string `yaml:"include"`
+	Exclude []string `yaml:"exclude"`
+}
+
+// OrderConfig configures how the reminder is sorted.
+type OrderConfig struct {
+	// Sections is either "config", "alphabetical" or
+	// "most-items". Defaults to "config".
+	Sections string `yaml:"sections"`
+	// Items is either "newest", "oldest" or "score" (highest first).
+	// Defaults to the order of the integration, i.e. the feed.
+	Items string `yaml:"items"`
+}
+
+// StalenessConfig configures when the open MRs of a project are
+// stale, by the age of the oldest one. Thresholds are disabled if empty.
+type StalenessConfig struct {
+	// Warn marks the project as warning. The ages older than it get
+	// bold, defaults to 48h for bolding only.
+	Warn string `yaml:"warn"`
+	// Escalate marks the project as critical, mentions
+	// the Mention and sends it to the Channel, if any.
+	Escalate string `yaml:"escalate"`
+	// Mention is a user or a user group, i.e. a Slack user group ID.
+	Mention string `yaml:"mention"`
+	Channel string `yaml:"channel"`
+	// Groups overrides the thresholds per group, empty fields
+	// inherit the global ones.
+	Groups []GroupStalenessConfig `yaml:"groups"`
+}
+
+type GroupStalenessConfig struct {
+	ID       int    `yaml:"id"`
+	Warn     string `yaml:"warn"`
+	Escalate string `yaml:"escalate"`
+	Mention  string `yaml:"mention"`
+	Channel  string `yaml:"channel"`
 }
 
 type RSSIntegrationConfig struct {
-	Enabled string            `yaml:"enabled"`
-	Sources []RSSSourceConfig `yaml:"sources"`
+	Enabled string `yaml:"enabled"`
+	// Schedule is the default schedule for the sources
+	// which do not declare their own, in daemon mode.
+	Schedule string `yaml:"schedule"`
+	// Timeout and Retries are the default fetch policy for the sources
+	// which do not declare their own. Defaults to 30s and no retry.
+	Timeout string `yaml:"timeout"`
+	Retries int    `yaml:"retries"`
+	// UserAgent is sent on each request, defaults to remind-us.
+	UserAgent string `yaml:"userAgent"`
+	// ReportFailures includes the sources that could not be
+	// fetched in the reminder as a warning, they are logged anyway.
+	ReportFailures bool              `yaml:"reportFailures"`
+	Sources        []RSSSourceConfig `yaml:"sources"`
+	// OPML imports the feeds as the sources on Load.
+	OPML  []RSSOPMLConfig `yaml:"opml"`
+	Order OrderConfig     `yaml:"order"`
+	// MaxItems is the maximum number of the items in a message,
+	// the rest are summarized. 0 means no limit.
+	MaxItems int `yaml:"maxItems"`
 }
 
 type RSSSourceConfig struct {
-	URL        string         `yaml:"url"`
-	Since      string         `yaml:"since"`
-	MatchTitle RSSMatchConfig `yaml:"matchTitle"`
+	URL string `yaml:"url"`
+	// Name tells the rules of the same URL apart, the URL is fetched once
+	// for all of them. The fetch settings of the first rule are used.
+	Name string `yaml:"name"`
+	// Channel overrides the channel of the alerter for the items of the rule.
+	Channel  string `yaml:"channel"`
+	Since    string `yaml:"since"`
+	Schedule string `yaml:"schedule"`
+	// Timeout is per attempt, Retries is the number of the
+	// attempts after the first one. They inherit if empty,
+	// Retries is a pointer to tell 0 apart from the unset.
+	Timeout string `yaml:"timeout"`
+	Retries *int   `yaml:"retries"`
+	// Headers are sent in addition, i.e. the auth tokens for the private feeds.
+	Headers    map[string]string `yaml:"headers"`
+	MatchTitle RSSMatchConfig    `yaml:"matchTitle"`
+	// MatchDescription matches the description and the content,
+	// HTML stripped. An item is included if any of the matchers match.
+	MatchDescription RSSMatchConfig `yaml:"matchDescription"`
+	MatchCategories  RSSMatchConfig `yaml:"matchCategories"`
+	// MatchAuthor matches the name or the email of the author.
+	MatchAuthor RSSMatchConfig `yaml:"matchAuthor"`
+	// Exclude skips the items matching any of them,
+	// it takes precedence over the matchers.
+	Exclude RSSExcludeConfig `yaml:"exclude"`
+	// Expression must hold for the items in addition to the matchers,
+	// it selects the items alone if there is no matcher, i.e.
+	// `title ~ "CVE" and not title ~ "(?i)rumor" and category == "kubernetes"`.
+	Expression string `yaml:"expression"`
+	// MaxItems is the maximum number of the items of the rule,
+	// the rest are summarized. 0 means no limit.
+	MaxItems int `yaml:"maxItems"`
+}
+
+type RSSExcludeConfig struct {
+	Title       RSSMatchConfig `yaml:"title"`
+	Description RSSMatchConfig `yaml:"description"`
+	Categories  RSSMatchConfig `yaml:"categories"`
+	Author      RSSMatchConfig `yaml:"author"`
 }
 
 type RSSMatchConfig struct {
@@ -58,14 +188,54 @@ type RSSMatchConfig struct {
 type IntegrationListenConfig struct {
 	Areas  []IntegrationAreaConfig
 	Groups []int
+	// Projects are the IDs or the paths of the projects
+	// to listen in addition to the projects of the groups.
+	Projects []string `yaml:"projects"`
+	// MaxProjects is the maximum number of projects
+	// listed for each group. 0 means no limit.
+	MaxProjects      int  `yaml:"maxProjects"`
+	IncludeSubgroups bool `yaml:"includeSubgroups"`
+	SkipArchived     bool `yaml:"skipArchived"`
+	SkipMirrors      bool `yaml:"skipMirrors"`
+	// Include and Exclude filter the projects of the groups
+	// and the listed ones by their paths, i.e. "foo/bar/baz".
+	Include ProjectFilterConfig `yaml:"include"`
+	Exclude ProjectFilterConfig `yaml:"exclude"`
+}
+
+type ProjectFilterConfig struct {
+	// Paths are the glob patterns, i.e. "foo/*".
+	Paths   []string `yaml:"paths"`
+	Regexes []string `yaml:"regexes"`
 }
 
 type IntegrationAreaConfig struct {
+	// Type is one of: MR, issue, pipeline, milestone.
+	Type string `yaml:"type"`
+	// DueWithin is the duration that a milestone is considered
+	// as due soon, only for the milestone type. Defaults to 168h.
+	DueWithin string `yaml:"dueWithin"`
+}
+
+type StateConfig struct {
+	// Type is the store type: file (default) or memory.
 	Type string `yaml:"type"`
+	Path string `yaml:"path"`
+	// Retention is how long the alerted items are remembered.
+	Retention string `yaml:"retention"`
 }
 
 type AlertConfig struct {
-	Slack *SlackAlertConfig `yaml:"slack"`
+	Slack   *SlackAlertConfig   `yaml:"slack"`
+	Teams   *TeamsAlertConfig   `yaml:"teams"`
+	Discord *DiscordAlertConfig `yaml:"discord"`
+	Email   *EmailAlertConfig   `yaml:"email"`
+	// Mattermost and RocketChat post Slack-like webhook payloads,
+	// adapted to the attachments each of them supports.
+	Mattermost *MattermostAlertConfig `yaml:"mattermost"`
+	RocketChat *RocketChatAlertConfig `yaml:"rocketChat"`
+	// Webhooks post the reminders to the arbitrary HTTP endpoints.
+	Webhooks []WebhookAlertConfig `yaml:"webhooks"`
 }
 
 type SlackAlertConfig struct {
@@ -74,6 +244,131 @@ type SlackAlertConfig struct {
 	Channel  string `yaml:"channel"`
 	Username string `yaml:"username"`
 	Icon     string `yaml:"icon"`
+	// Token is the bot token, required to look the users up
+	// by email and to send the direct messages.
+	Token string           `yaml:"token"`
+	Users SlackUsersConfig `yaml:"users"`
+	// DirectMessages sends the personal sections, i.e. the GitLab
+	// digests, to the users directly. Otherwise they are skipped.
+	DirectMessages bool `yaml:"directMessages"`
+}
+
+type TeamsAlertConfig struct {
+	Enabled string `yaml:"enabled"`
+	Webhook string `yaml:"webhook"`
+	// Channels maps the channels of the sections to their webhooks,
+	// since a Teams webhook posts to a single channel. The sections
+	// of the channels not mapped are posted to Webhook.
+	Channels map[string]string `yaml:"channels"`
+	// Users maps the usernames or the emails of the integration
+	// to the Teams users to mention, by their UPNs or AAD object IDs.
+	// The users not mapped are mentioned by their emails, if any.
+	Users map[string]string `yaml:"users"`
+}
+
+type DiscordAlertConfig struct {
+	Enabled  string `yaml:"enabled"`
+	Webhook  string `yaml:"webhook"`
+	Username string `yaml:"username"`
+	// Avatar is the URL of the avatar of the webhook.
+	Avatar string `yaml:"avatar"`
+	// Channels maps the channels of the sections to their webhooks,
+	// since a Discord webhook posts to a single channel. The sections
+	// of the channels not mapped are posted to Webhook.
+	Channels map[string]string `yaml:"channels"`
+	// Users maps the usernames or the emails of the integration to the
+	// Discord user IDs to mention, and the user groups to the role IDs.
+	Users map[string]string `yaml:"users"`
+}
+
+type EmailAlertConfig struct {
+	Enabled  string `yaml:"enabled"`
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	// TLS is either "starttls", "tls" (implicit) or "none".
+	// Defaults to STARTTLS if the server supports it.
+	TLS string `yaml:"tls"`
+	// SkipVerify skips the verification of the certificate of the server.
+	SkipVerify bool     `yaml:"skipVerify"`
+	From       string   `yaml:"from"`
+	To         []string `yaml:"to"`
+	// Subject is a text/template of the reminder, i.e. "{{.Source}}: {{.Items}} item(s)".
+	Subject string `yaml:"subject"`
+	// Channels maps the channels of the sections to their recipients.
+	// The sections of the channels not mapped are sent to To.
+	Channels map[string][]string `yaml:"channels"`
+	// DirectMessages sends the personal sections, i.e. the GitLab
+	// digests, to the emails of the users. Otherwise they are skipped.
+	DirectMessages bool `yaml:"directMessages"`
+}
+
+type MattermostAlertConfig struct {
+	Enabled  string `yaml:"enabled"`
+	Webhook  string `yaml:"webhook"`
+	Channel  string `yaml:"channel"`
+	Username string `yaml:"username"`
+	// Icon is the URL of the icon of the webhook.
+	Icon string `yaml:"icon"`
+	// Users maps the usernames or the emails of the integration
+	// to the Mattermost usernames to mention. The users not mapped
+	// are mentioned by their usernames.
+	Users map[string]string `yaml:"users"`
+	// DirectMessages sends the personal sections, i.e. the GitLab digests,
+	// to the users via the "@username" channel, it requires the webhook
+	// not to be locked to a channel. Otherwise they are skipped.
+	DirectMessages bool `yaml:"directMessages"`
+}
+
+type RocketChatAlertConfig struct {
+	Enabled string `yaml:"enabled"`
+	Webhook string `yaml:"webhook"`
+	// Channel overrides the channel of the integration, i.e. "#general".
+	Channel string `yaml:"channel"`
+	// Alias is the name the messages are posted by.
+	Alias string `yaml:"alias"`
+	// Avatar is the URL of the avatar, Emoji is used instead if set.
+	Avatar string `yaml:"avatar"`
+	Emoji  string `yaml:"emoji"`
+	// Users maps the usernames or the emails of the integration
+	// to the Rocket.Chat usernames to mention. The users not mapped
+	// are mentioned by their usernames.
+	Users map[string]string `yaml:"users"`
+	// DirectMessages sends the personal sections, i.e. the GitLab
+	// digests, to the users via the "@username" channel.
+	// Otherwise they are skipped.
+	DirectMessages bool `yaml:"directMessages"`
+}
+
+// WebhookAlertConfig configures a generic webhook. The URL, Method,
+// Headers and Body are Go templates of the reminder of a channel.
+type WebhookAlertConfig struct {
+	Name    string `yaml:"name"`
+	Enabled string `yaml:"enabled"`
+	URL     string `yaml:"url"`
+	// Method defaults to POST.
+	Method  string            `yaml:"method"`
+	Headers map[string]string `yaml:"headers"`
+	// Body defaults to the reminder in JSON.
+	Body string `yaml:"body"`
+	// Secret signs the body by HMAC-SHA256 in SignatureHeader,
+	// i.e. "sha256=<hex>". The body is not signed if it is empty.
+	Secret          string `yaml:"secret"`
+	SignatureHeader string `yaml:"signatureHeader"`
+	// Timeout is per attempt, Retries is the number of the attempts
+	// after the first one on 5xx. Defaults to 30s and no retry.
+	Timeout string `yaml:"timeout"`
+	Retries int    `yaml:"retries"`
+}
+
+type SlackUsersConfig struct {
+	// Mapping maps the usernames or the emails of the
+	// integrations to the Slack user IDs, i.e. "dentrax: U024BE7LH".
+	Mapping       map[string]string `yaml:"mapping"`
+	LookupByEmail bool              `yaml:"lookupByEmail"`
+	// CacheTTL is how long the looked up users are cached. Defaults to 24h.
+	CacheTTL string `yaml:"cacheTTL"`
 }
 
 func Load(path string) (*Config, error) {
@@ -94,5 +389,19 @@ func Load(path string) (*Config, error) {
 		return nil, errors.Wrap(err, "unable to unmarshal to Config struct")
 	}
 
+	if c.Integrations.RSS != nil {
+		if err := c.Integrations.RSS.ExpandOPML(filepath.Dir(path)); err != nil {
+			return nil, err
+		}
+	}
+
 	return c, err
 }
+
+// WithRSS returns a copy of the Integrations
+// which has the given RSS config.
+func (i Integrations) WithRSS(c *RSSIntegrationConfig) Integrations {
+	i.RSS = c
+
+	return i
+}
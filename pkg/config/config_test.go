@@ -67,13 +67,48 @@ func TestLoad(t *testing.T) {
 					},
 				},
 				AlertConfig{
-					&SlackAlertConfig{
+					Slack: &SlackAlertConfig{
 						Webhook:  "webhook",
 						Channel:  "#channel",
 						Username: "Username",
 						Icon:     ":icon:",
 					},
 				},
+				nil,
+			},
+			false,
+		},
+		{
+			"it should import the OPML files",
+			"../../testdata/config_opml.yaml",
+			&Config{
+				Integrations{
+					RSS: &RSSIntegrationConfig{
+						Enabled: "true",
+						Sources: []RSSSourceConfig{
+							{URL: "https://example.com/declared.rss", Since: "1h"},
+							{URL: "https://www.reddit.com/r/kubernetes/new/.rss", Channel: "#security", Since: "2h", MatchTitle: RSSMatchConfig{Contains: []string{"CVE"}}},
+							{URL: "https://www.reddit.com/r/golang/new/.rss", Channel: "#feeds", Since: "24h", MatchTitle: RSSMatchConfig{Contains: []string{"release"}}},
+							{URL: "https://hnrss.org/frontpage", Channel: "#feeds", Since: "2h", MatchTitle: RSSMatchConfig{Contains: []string{"release"}}},
+						},
+						OPML: []RSSOPMLConfig{
+							{
+								Path: "feeds.opml",
+								Defaults: RSSSourceConfig{
+									Channel:    "#feeds",
+									Since:      "2h",
+									MatchTitle: RSSMatchConfig{Contains: []string{"release"}},
+								},
+								Categories: map[string]RSSSourceConfig{
+									"Security": {Channel: "#security", MatchTitle: RSSMatchConfig{Contains: []string{"CVE"}}},
+									"Releases": {Since: "24h"},
+								},
+							},
+						},
+					},
+				},
+				AlertConfig{},
+				nil,
 			},
 			false,
 		},
@@ -0,0 +1,227 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// RSSOPMLConfig imports the feeds of an OPML file as the sources.
+type RSSOPMLConfig struct {
+	// Path is relative to the config file, if not absolute.
+	Path string `yaml:"path"`
+	// Defaults is the template of the sources, except the URL.
+	Defaults RSSSourceConfig `yaml:"defaults"`
+	// Categories override the Defaults by the OPML category, which is
+	// the title of the parent outline or the 'category' attribute.
+	// The empty name, channel, since, schedule, timeout, retries, headers
+	// and max items inherit the Defaults. The match rules, the excludes and
+	// the expression replace the ones of the Defaults, unless the template
+	// declares none. The declared sources take precedence over both.
+	//
+	// map: K: Category, case-insensitive, V: Template of the sources
+	Categories map[string]RSSSourceConfig `yaml:"categories"`
+}
+
+type opmlDocument struct {
+	Outlines []opmlOutline `xml:"body>outline"`
+}
+
+type opmlOutline struct {
+	Text     string        `xml:"text,attr"`
+	Title    string        `xml:"title,attr"`
+	XMLURL   string        `xml:"xmlUrl,attr"`
+	Category string        `xml:"category,attr"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+func (o opmlOutline) title() string {
+	if o.Title != "" {
+		return o.Title
+	}
+
+	return o.Text
+}
+
+// categories returns the categories of the outline, the ones of the
+// 'category' attribute first, i.e. "/Security,/Kubernetes".
+func (o opmlOutline) categories(parent string) []string {
+	var result []string
+
+	for _, c := range strings.Split(o.Category, ",") {
+		if c = strings.Trim(strings.TrimSpace(c), "/"); c != "" {
+			result = append(result, c)
+		}
+	}
+
+	if parent != "" {
+		result = append(result, parent)
+	}
+
+	return result
+}
+
+// ExpandOPML appends the feeds of the OPML files to the sources, the
+// relative paths are resolved by the given directory. The sources
+// already declared are not overridden.
+func (c *RSSIntegrationConfig) ExpandOPML(dir string) error {
+	seen := make(map[string]bool, len(c.Sources))
+
+	for _, s := range c.Sources {
+		seen[s.URL] = true
+	}
+
+	for _, o := range c.OPML {
+		p := o.Path
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(dir, p)
+		}
+
+		sources, err := o.sources(p)
+		if err != nil {
+			return errors.Wrapf(err, "unable to import OPML file: '%s'", o.Path)
+		}
+
+		for _, s := range sources {
+			if seen[s.URL] {
+				continue
+			}
+
+			seen[s.URL] = true
+			c.Sources = append(c.Sources, s)
+		}
+	}
+
+	return nil
+}
+
+func (o RSSOPMLConfig) sources(path string) ([]RSSSourceConfig, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc opmlDocument
+
+	if err := xml.Unmarshal(b, &doc); err != nil {
+		return nil, errors.Wrap(err, "incorrect OPML")
+	}
+
+	categories := make(map[string]RSSSourceConfig, len(o.Categories))
+
+	for k, v := range o.Categories {
+		categories[strings.ToLower(k)] = v
+	}
+
+	var result []RSSSourceConfig
+
+	var walk func(outlines []opmlOutline, parent string)
+
+	walk = func(outlines []opmlOutline, parent string) {
+		for _, outline := range outlines {
+			if outline.XMLURL == "" {
+				walk(outline.Outlines, outline.title())
+				continue
+			}
+
+			source := o.Defaults
+
+			for _, c := range outline.categories(parent) {
+				if t, ok := categories[strings.ToLower(c)]; ok {
+					source = mergeSource(o.Defaults, t)
+					break
+				}
+			}
+
+			source.URL = outline.XMLURL
+			result = append(result, source)
+		}
+	}
+
+	walk(doc.Outlines, "")
+
+	return result, nil
+}
+
+// mergeSource returns the template, where its empty fields are inherited.
+// The rules are inherited as a whole, so a template declaring only the
+// since still alerts, where a template declaring a matcher does not
+// inherit the other matchers of the defaults.
+func mergeSource(defaults, template RSSSourceConfig) RSSSourceConfig {
+	if !template.hasRules() {
+		template.MatchTitle = defaults.MatchTitle
+		template.MatchDescription = defaults.MatchDescription
+		template.MatchCategories = defaults.MatchCategories
+		template.MatchAuthor = defaults.MatchAuthor
+		template.Exclude = defaults.Exclude
+		template.Expression = defaults.Expression
+	}
+
+	if template.Name == "" {
+		template.Name = defaults.Name
+	}
+
+	if template.Channel == "" {
+		template.Channel = defaults.Channel
+	}
+
+	if template.Since == "" {
+		template.Since = defaults.Since
+	}
+
+	if template.Schedule == "" {
+		template.Schedule = defaults.Schedule
+	}
+
+	if template.Timeout == "" {
+		template.Timeout = defaults.Timeout
+	}
+
+	if template.Retries == nil {
+		template.Retries = defaults.Retries
+	}
+
+	if template.Headers == nil {
+		template.Headers = defaults.Headers
+	}
+
+	if template.MaxItems == 0 {
+		template.MaxItems = defaults.MaxItems
+	}
+
+	return template
+}
+
+// hasRules returns true if the source declares any
+// of the matchers, the excludes or the expression.
+func (s RSSSourceConfig) hasRules() bool {
+	for _, m := range []RSSMatchConfig{
+		s.MatchTitle, s.MatchDescription, s.MatchCategories, s.MatchAuthor,
+		s.Exclude.Title, s.Exclude.Description, s.Exclude.Categories, s.Exclude.Author,
+	} {
+		if len(m.Regexes) > 0 || len(m.Contains) > 0 {
+			return true
+		}
+	}
+
+	return s.Expression != ""
+}
@@ -0,0 +1,353 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitlab
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Dentrax/remind-us/pkg/config"
+	"github.com/Dentrax/remind-us/pkg/reminder"
+	"github.com/hako/durafmt"
+	"github.com/pkg/errors"
+	"github.com/xanzy/go-gitlab"
+)
+
+// Area is what is scanned in each project.
+type Area string
+
+const (
+	AreaMR        Area = "mr"
+	AreaIssue     Area = "issue"
+	AreaPipeline  Area = "pipeline"
+	AreaMilestone Area = "milestone"
+)
+
+// defaultDueWithin is the default duration that a
+// milestone is considered as due soon.
+const defaultDueWithin = 7 * 24 * time.Hour
+
+// parseAreas parses the configured areas, defaults to MRs only.
+// "PR" is accepted as MR for the backward compatibility.
+func parseAreas(areas []config.IntegrationAreaConfig) (map[Area]time.Duration, error) {
+	result := make(map[Area]time.Duration, len(areas))
+
+	if len(areas) == 0 {
+		result[AreaMR] = 0
+
+		return result, nil
+	}
+
+	for _, a := range areas {
+		var area Area
+
+		switch strings.ToLower(a.Type) {
+		case "mr", "pr", "merge_request":
+			area = AreaMR
+		case "issue", "issues":
+			area = AreaIssue
+		case "pipeline", "pipelines":
+			area = AreaPipeline
+		case "milestone", "milestones":
+			area = AreaMilestone
+		default:
+			return nil, errors.Errorf("unknown area type: '%s'", a.Type)
+		}
+
+		var dueWithin time.Duration
+
+		if area == AreaMilestone {
+			dueWithin = defaultDueWithin
+
+			if a.DueWithin != "" {
+				d, err := time.ParseDuration(a.DueWithin)
+				if err != nil {
+					return nil, errors.Wrapf(err, "incorrect 'dueWithin' pattern: '%s'", a.DueWithin)
+				}
+
+				dueWithin = d
+			}
+		}
+
+		result[area] = dueWithin
+	}
+
+	return result, nil
+}
+
+// listProjectIssues walks all the pages of the opened issues
+// of the project. Returns the number of pages fetched.
+func listProjectIssues(git *gitlab.Client, pid int) ([]*gitlab.Issue, int, error) {
+	var result []*gitlab.Issue
+
+	state := "opened"
+
+	opt := &gitlab.ListProjectIssuesOptions{
+		ListOptions: gitlab.ListOptions{
+			Page:    1,
+			PerPage: perPage,
+		},
+		State: &state,
+	}
+
+	pages, err := walkPages(&opt.Page, nil, func() (*gitlab.Response, error) {
+		issues, resp, err := git.Issues.ListProjectIssues(pid, opt)
+		result = append(result, issues...)
+
+		return resp, err
+	})
+
+	return result, pages, err
+}
+
+// getFailedPipeline returns the latest pipeline of the default
+// branch of the project if it is failed, nil otherwise.
+func getFailedPipeline(git *gitlab.Client, p *gitlab.Project) (*gitlab.PipelineInfo, error) {
+	if p.DefaultBranch == "" {
+		return nil, nil
+	}
+
+	orderBy, sort := "id", "desc"
+
+	pipelines, _, err := git.Pipelines.ListProjectPipelines(p.ID, &gitlab.ListProjectPipelinesOptions{
+		ListOptions: gitlab.ListOptions{
+			Page:    1,
+			PerPage: 1,
+		},
+		Ref:     &p.DefaultBranch,
+		OrderBy: &orderBy,
+		Sort:    &sort,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pipelines) == 0 || pipelines[0].Status != "failed" {
+		return nil, nil
+	}
+
+	return pipelines[0], nil
+}
+
+// listDueMilestones lists the active milestones of the project
+// which are due in the given duration, or already overdue.
+func listDueMilestones(git *gitlab.Client, pid int, dueWithin time.Duration) ([]*gitlab.Milestone, int, error) {
+	var result []*gitlab.Milestone
+
+	state := "active"
+
+	opt := &gitlab.ListMilestonesOptions{
+		ListOptions: gitlab.ListOptions{
+			Page:    1,
+			PerPage: perPage,
+		},
+		State: &state,
+	}
+
+	deadline := time.Now().Add(dueWithin)
+
+	pages, err := walkPages(&opt.Page, nil, func() (*gitlab.Response, error) {
+		milestones, resp, err := git.Milestones.ListMilestones(pid, opt)
+
+		for _, m := range milestones {
+			if m.DueDate != nil && time.Time(*m.DueDate).Before(deadline) {
+				result = append(result, m)
+			}
+		}
+
+		return resp, err
+	})
+
+	return result, pages, err
+}
+
+// newProjectSection returns a section with the header
+// and the footer of the project, for each area.
+func (g *GitLab) newProjectSection(p *gitlab.Project) reminder.Section {
+	s := reminder.Section{
+		Title:     p.Name,
+		Link:      p.HTTPURLToRepo,
+		Icon:      p.AvatarURL,
+		Severity:  reminder.SeverityOK,
+		Timestamp: time.Now(),
+	}
+
+	if p.Namespace != nil {
+		s.Footer = p.Namespace.FullPath
+		s.FooterIcon = fmt.Sprintf("%s%s", g.config.BaseURL, p.Namespace.AvatarURL)
+	}
+
+	return s
+}
+
+func (g *GitLab) generateIssueSection(p GroupProjectScanResponse, st staleness) (reminder.Section, bool) {
+	var overdue, unassigned []reminder.Item
+
+	for _, i := range p.Issues {
+		item := reminder.Item{
+			Status: reminder.StatusFailed,
+			Title:  i.Title,
+			Link:   i.WebURL,
+			Time:   i.CreatedAt,
+		}
+
+		if i.Author != nil {
+			item.Author = &reminder.User{
+				Username: i.Author.Username,
+				Name:     i.Author.Name,
+			}
+		}
+
+		if i.DueDate != nil && time.Time(*i.DueDate).Before(time.Now()) {
+			due := time.Time(*i.DueDate)
+			item.Details = reminder.Text{reminder.Plain("(due "), getTimeText(&due, st.boldAfter()), reminder.Plain(" ago)")}
+			overdue = append(overdue, item)
+
+			continue
+		}
+
+		if len(i.Assignees) == 0 && i.Assignee == nil {
+			item.Status = reminder.StatusNone
+			if i.CreatedAt != nil {
+				item.Details = reminder.Text{reminder.Plain("(created "), getTimeText(i.CreatedAt, st.boldAfter()), reminder.Plain(" ago)")}
+			}
+			unassigned = append(unassigned, item)
+		}
+	}
+
+	total := len(overdue) + len(unassigned)
+
+	if total == 0 {
+		return reminder.Section{}, false
+	}
+
+	s := g.newProjectSection(p.Project)
+
+	s.MoreLink = fmt.Sprintf("%s/issues?state=opened", p.Project.WebURL)
+	s.Summary = reminder.Text{
+		reminder.Plain(fmt.Sprintf("There %s ", isAre(total))),
+		reminder.Link(countText(total, "open issue", "open issues"), s.MoreLink),
+		reminder.Plain(" needing attention in "),
+		reminder.Link(p.Project.NameWithNamespace, p.Project.WebURL),
+		reminder.Plain("."),
+	}
+
+	if len(overdue) > 0 {
+		s.Severity = reminder.SeverityWarning
+		s.Groups = append(s.Groups, reminder.NewGroup(overdue, func(n int) string {
+			return fmt.Sprintf("%s %s past due", countText(n, "issue", "issues"), isAre(n))
+		}))
+	}
+
+	if len(unassigned) > 0 {
+		s.Groups = append(s.Groups, reminder.NewGroup(unassigned, func(n int) string {
+			return fmt.Sprintf("%s %s unassigned", countText(n, "issue", "issues"), isAre(n))
+		}))
+	}
+
+	return s, true
+}
+
+func (g *GitLab) generatePipelineSection(p GroupProjectScanResponse, st staleness) (reminder.Section, bool) {
+	if p.Pipeline == nil {
+		return reminder.Section{}, false
+	}
+
+	s := g.newProjectSection(p.Project)
+
+	s.Severity = reminder.SeverityCritical
+	s.Summary = reminder.Text{
+		reminder.Plain("The latest pipeline of "),
+		reminder.Link(p.Pipeline.Ref, fmt.Sprintf("%s/-/pipelines?ref=%s", p.Project.WebURL, p.Pipeline.Ref)),
+		reminder.Plain(" has failed in "),
+		reminder.Link(p.Project.NameWithNamespace, p.Project.WebURL),
+		reminder.Plain("."),
+	}
+
+	item := reminder.Item{
+		Status: reminder.StatusFailed,
+		Title:  fmt.Sprintf("Pipeline #%d", p.Pipeline.ID),
+		Link:   p.Pipeline.WebURL,
+		Time:   p.Pipeline.CreatedAt,
+	}
+
+	if p.Pipeline.UpdatedAt != nil {
+		item.Details = reminder.Text{reminder.Plain("(failed "), getTimeText(p.Pipeline.UpdatedAt, st.boldAfter()), reminder.Plain(" ago)")}
+	}
+
+	s.Items = []reminder.Item{item}
+
+	return s, true
+}
+
+func (g *GitLab) generateMilestoneSection(p GroupProjectScanResponse, st staleness) (reminder.Section, bool) {
+	if len(p.Milestones) == 0 {
+		return reminder.Section{}, false
+	}
+
+	s := g.newProjectSection(p.Project)
+
+	s.MoreLink = fmt.Sprintf("%s/-/milestones?state=opened", p.Project.WebURL)
+	s.Summary = reminder.Text{
+		reminder.Plain(fmt.Sprintf("There %s ", isAre(len(p.Milestones)))),
+		reminder.Link(countText(len(p.Milestones), "milestone", "milestones"), s.MoreLink),
+		reminder.Plain(" due soon in "),
+		reminder.Link(p.Project.NameWithNamespace, p.Project.WebURL),
+		reminder.Plain("."),
+	}
+
+	items := make([]reminder.Item, len(p.Milestones))
+
+	for i, m := range p.Milestones {
+		due := time.Time(*m.DueDate)
+
+		items[i] = reminder.Item{
+			Title: m.Title,
+			Link:  m.WebURL,
+			Time:  &due,
+		}
+
+		if due.Before(time.Now()) {
+			s.Severity = reminder.SeverityWarning
+			items[i].Status = reminder.StatusFailed
+			items[i].Details = reminder.Text{reminder.Plain("(due "), getTimeText(&due, st.boldAfter()), reminder.Plain(" ago)")}
+		} else {
+			items[i].Details = reminder.Text{reminder.Plain(fmt.Sprintf("(due in %s)", durafmt.Parse(time.Until(due)).LimitFirstN(1).String()))}
+		}
+	}
+
+	s.Items = items
+
+	return s, true
+}
+
+func countText(n int, singular, plural string) string {
+	if n > 1 {
+		return fmt.Sprintf("%d %s", n, plural)
+	}
+
+	return fmt.Sprintf("%d %s", n, singular)
+}
+
+func isAre(n int) string {
+	if n > 1 {
+		return "are"
+	}
+
+	return "is"
+}
@@ -0,0 +1,221 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitlab
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Dentrax/remind-us/pkg/config"
+	"github.com/xanzy/go-gitlab"
+)
+
+// defaultWorkers is the default number of the projects scanned concurrently.
+const defaultWorkers = 4
+
+const (
+	// minBackoff and maxBackoff bound the exponential backoff,
+	// used if GitLab does not tell how long to wait.
+	minBackoff = 500 * time.Millisecond
+	maxBackoff = 30 * time.Second
+
+	// maxRateLimitWait bounds the wait told by GitLab.
+	maxRateLimitWait = 5 * time.Minute
+
+	// lowRateLimitRatio is the ratio of the remaining requests to the
+	// limit, below which the requests are paced until the reset.
+	lowRateLimitRatio = 0.1
+)
+
+// callCounter counts the API calls made by the client, including the retries.
+type callCounter struct {
+	next  http.RoundTripper
+	calls int64
+}
+
+func (c *callCounter) RoundTrip(req *http.Request) (*http.Response, error) {
+	atomic.AddInt64(&c.calls, 1)
+
+	return c.next.RoundTrip(req)
+}
+
+func (c *callCounter) Calls() int64 {
+	return atomic.LoadInt64(&c.calls)
+}
+
+// pacer slows the requests down as the rate limit runs out, the
+// remaining requests are spread evenly until the reset of the window.
+// It is shared by the workers, so they are paced together.
+type pacer struct {
+	next http.RoundTripper
+
+	mu sync.Mutex
+	// interval is the wait between the requests, 0 if not paced.
+	interval time.Duration
+	// at is when the next request is allowed.
+	at time.Time
+}
+
+func (p *pacer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if wait := p.reserve(time.Now()); wait > 0 {
+		t := time.NewTimer(wait)
+		defer t.Stop()
+
+		select {
+		case <-t.C:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+
+	resp, err := p.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	p.observe(resp.Header, time.Now())
+
+	return resp, nil
+}
+
+// reserve reserves the next slot, it returns how long to wait for it.
+func (p *pacer) reserve(now time.Time) time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	at := p.at
+	if at.Before(now) {
+		at = now
+	}
+
+	p.at = at.Add(p.interval)
+
+	return at.Sub(now)
+}
+
+// observe paces the requests by the RateLimit-Limit, RateLimit-Remaining
+// and RateLimit-Reset headers, if the remaining requests are low.
+func (p *pacer) observe(h http.Header, now time.Time) {
+	limit, err := strconv.Atoi(h.Get("RateLimit-Limit"))
+	if err != nil || limit <= 0 {
+		return
+	}
+
+	remaining, err := strconv.Atoi(h.Get("RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+
+	reset, err := strconv.ParseInt(h.Get("RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return
+	}
+
+	var interval time.Duration
+
+	if float64(remaining) < float64(limit)*lowRateLimitRatio {
+		interval = time.Unix(reset, 0).Sub(now) / time.Duration(remaining+1)
+	}
+
+	if interval < 0 {
+		interval = 0
+	}
+
+	if interval > maxRateLimitWait {
+		interval = maxRateLimitWait
+	}
+
+	p.mu.Lock()
+	p.interval = interval
+	p.mu.Unlock()
+}
+
+// newClient returns a GitLab client pacing and backing off as the rate
+// limit headers tell, and the counter of the API calls made by it.
+func newClient(c *config.GitLabIntegrationConfig) (*gitlab.Client, *callCounter, error) {
+	counter := &callCounter{
+		next: http.DefaultTransport,
+	}
+
+	git, err := gitlab.NewClient(c.Token,
+		gitlab.WithBaseURL(c.BaseURL),
+		gitlab.WithHTTPClient(&http.Client{Transport: &pacer{next: counter}}),
+		gitlab.WithCustomBackoff(backoff),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return git, counter, nil
+}
+
+// backoff waits as the Retry-After or the RateLimit-Reset headers
+// tell, if it is rate limited. Otherwise, it waits exponentially by
+// the attempt, since GitLab sends the RateLimit-Reset on each response.
+// The min and max of the client are ignored, they are too short.
+func backoff(_, _ time.Duration, attemptNum int, resp *http.Response) time.Duration {
+	if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+		if wait, ok := rateLimitWait(resp.Header, time.Now()); ok {
+			return wait
+		}
+	}
+
+	wait := minBackoff << uint(attemptNum)
+	if wait <= 0 || wait > maxBackoff {
+		return maxBackoff
+	}
+
+	return wait
+}
+
+// rateLimitWait parses the Retry-After header, either in seconds or
+// an HTTP date, then the RateLimit-Reset header as a Unix timestamp.
+func rateLimitWait(h http.Header, now time.Time) (time.Duration, bool) {
+	var wait time.Duration
+
+	if v := h.Get("Retry-After"); v != "" {
+		if s, err := strconv.Atoi(v); err == nil && s >= 0 {
+			wait = time.Duration(s) * time.Second
+		} else if t, err := http.ParseTime(v); err == nil {
+			wait = t.Sub(now)
+		} else {
+			return 0, false
+		}
+	} else if v := h.Get("RateLimit-Reset"); v != "" {
+		reset, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0, false
+		}
+
+		wait = time.Unix(reset, 0).Sub(now)
+	} else {
+		return 0, false
+	}
+
+	if wait < 0 {
+		wait = 0
+	}
+
+	if wait > maxRateLimitWait {
+		wait = maxRateLimitWait
+	}
+
+	return wait, true
+}
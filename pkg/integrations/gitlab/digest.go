@@ -0,0 +1,192 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitlab
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Dentrax/remind-us/pkg/reminder"
+	"github.com/pkg/errors"
+)
+
+// Mode is how the MRs are grouped in the reminder.
+type Mode string
+
+const (
+	ModeProject Mode = "project"
+	ModeDigest  Mode = "digest"
+	ModeBoth    Mode = "both"
+)
+
+func parseMode(mode string) (Mode, error) {
+	switch m := Mode(strings.ToLower(mode)); m {
+	case "":
+		return ModeProject, nil
+	case ModeProject, ModeDigest, ModeBoth:
+		return m, nil
+	default:
+		return "", errors.Errorf("unknown mode: '%s'", mode)
+	}
+}
+
+// digest collects the open MRs concerning a user.
+type digest struct {
+	user reminder.User
+
+	// awaiting and reviewed are the MRs that the user
+	// is a reviewer or an assignee of, but not the author.
+	awaiting []reminder.Item
+	reviewed []reminder.Item
+
+	// conflicts are the MRs of the user having conflicts.
+	conflicts []reminder.Item
+}
+
+// generateDigestSections regroups the open MRs by the reviewers,
+// assignees and authors, in order of appearance, by the same
+// review classification of the project sections.
+func (g *GitLab) generateDigestSections() []reminder.Section {
+	var order []string
+
+	digests := make(map[string]*digest)
+
+	get := func(u *reminder.User) *digest {
+		k := strings.ToLower(u.Username)
+
+		d, ok := digests[k]
+		if !ok {
+			d = &digest{user: *u}
+			digests[k] = d
+			order = append(order, k)
+		}
+
+		return d
+	}
+
+	for _, r := range g.Result {
+		st := g.stalenessOf(r.GroupID)
+
+		for _, p := range r.Projects {
+			for _, m := range p.MRs {
+				if !strings.EqualFold(m.State, "opened") {
+					continue
+				}
+
+				item := g.newMRItem(p, m, st)
+				item.Title = fmt.Sprintf("%s: %s", p.Project.Name, item.Title)
+
+				if m.HasConflicts && item.Author != nil {
+					d := get(item.Author)
+					d.conflicts = append(d.conflicts, item)
+				}
+
+				for _, u := range participants(item) {
+					u := u
+					d := get(&u)
+
+					if isReviewed(m) {
+						d.reviewed = append(d.reviewed, item)
+					} else {
+						d.awaiting = append(d.awaiting, item)
+					}
+				}
+			}
+		}
+	}
+
+	sections := make([]reminder.Section, 0, len(order))
+
+	for _, k := range order {
+		sections = append(sections, digests[k].section())
+	}
+
+	return sections
+}
+
+// participants returns the reviewers and the assignees
+// of the MR item without duplicates, except the author.
+func participants(item reminder.Item) []reminder.User {
+	var result []reminder.User
+
+	seen := make(map[string]bool)
+
+	if item.Author != nil {
+		seen[strings.ToLower(item.Author.Username)] = true
+	}
+
+	for _, u := range append(append([]reminder.User{}, item.Reviewers...), item.Assignees...) {
+		k := strings.ToLower(u.Username)
+
+		if !seen[k] {
+			seen[k] = true
+			result = append(result, u)
+		}
+	}
+
+	return result
+}
+
+func (d *digest) section() reminder.Section {
+	user := d.user
+
+	s := reminder.Section{
+		Title:     fmt.Sprintf("GitLab digest of %s", user.Username),
+		Severity:  reminder.SeverityOK,
+		Recipient: &user,
+	}
+
+	var titles []string
+
+	add := func(items []reminder.Item, title func(n int) string) {
+		g := reminder.NewGroup(items, title)
+
+		titles = append(titles, g.Title)
+		s.Groups = append(s.Groups, g)
+	}
+
+	if len(d.awaiting) > 0 {
+		add(d.awaiting, func(n int) string {
+			return fmt.Sprintf("%s waiting for your review", countText(n, "MR", "MRs"))
+		})
+	}
+
+	if len(d.reviewed) > 0 {
+		add(d.reviewed, func(n int) string {
+			return fmt.Sprintf("%s reviewed and waiting", countText(n, "MR", "MRs"))
+		})
+	}
+
+	if len(d.conflicts) > 0 {
+		s.Severity = reminder.SeverityWarning
+		add(d.conflicts, func(n int) string {
+			return fmt.Sprintf("%d of your MRs %s conflicts", n, hasHave(n))
+		})
+	}
+
+	s.Summary = reminder.Text{reminder.Plain(strings.Join(titles, ", ") + ".")}
+
+	return s
+}
+
+func hasHave(n int) string {
+	if n > 1 {
+		return "have"
+	}
+
+	return "has"
+}
@@ -0,0 +1,233 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitlab
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/Dentrax/remind-us/pkg/config"
+	"github.com/pkg/errors"
+	"github.com/xanzy/go-gitlab"
+)
+
+// projectFilter decides whether a project, of a group or listed, should be scanned.
+type projectFilter struct {
+	include pathMatcher
+	exclude pathMatcher
+
+	skipArchived bool
+	skipMirrors  bool
+}
+
+// pathMatcher matches a project path by either
+// a glob pattern or a compiled RegExp.
+type pathMatcher struct {
+	globs   []string
+	regexes []*regexp.Regexp
+}
+
+func newProjectFilter(c config.IntegrationListenConfig) (*projectFilter, error) {
+	include, err := newPathMatcher(c.Include)
+	if err != nil {
+		return nil, errors.Wrap(err, "incorrect 'include' filter")
+	}
+
+	exclude, err := newPathMatcher(c.Exclude)
+	if err != nil {
+		return nil, errors.Wrap(err, "incorrect 'exclude' filter")
+	}
+
+	return &projectFilter{
+		include:      include,
+		exclude:      exclude,
+		skipArchived: c.SkipArchived,
+		skipMirrors:  c.SkipMirrors,
+	}, nil
+}
+
+func newPathMatcher(c config.ProjectFilterConfig) (pathMatcher, error) {
+	m := pathMatcher{
+		globs:   c.Paths,
+		regexes: make([]*regexp.Regexp, len(c.Regexes)),
+	}
+
+	for _, glob := range c.Paths {
+		if _, err := path.Match(glob, ""); err != nil {
+			return m, errors.Wrapf(err, "incorrect glob pattern: '%s'", glob)
+		}
+	}
+
+	for i, regex := range c.Regexes {
+		re, err := regexp.Compile(regex)
+		if err != nil {
+			return m, errors.Wrapf(err, "incorrect RegExp pattern: '%s'", regex)
+		}
+
+		m.regexes[i] = re
+	}
+
+	return m, nil
+}
+
+func (m pathMatcher) empty() bool {
+	return len(m.globs) == 0 && len(m.regexes) == 0
+}
+
+func (m pathMatcher) match(p string) bool {
+	if matchAny(m.globs, p) {
+		return true
+	}
+
+	for _, re := range m.regexes {
+		if re.MatchString(p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Match reports whether the project passes the filter. A project must
+// match one of the includes, if any, and must not match any of the excludes.
+func (f *projectFilter) Match(p *gitlab.Project) bool {
+	if f.skipArchived && p.Archived {
+		return false
+	}
+
+	if f.skipMirrors && p.Mirror {
+		return false
+	}
+
+	if !f.include.empty() && !f.include.match(p.PathWithNamespace) {
+		return false
+	}
+
+	return !f.exclude.match(p.PathWithNamespace)
+}
+
+// draftPrefixes are the title prefixes that GitLab marks the MRs as draft.
+var draftPrefixes = []string{"draft:", "[draft]", "(draft)", "wip:", "[wip]"}
+
+// mrFilter decides whether an open MR should be reminded.
+type mrFilter struct {
+	skipDrafts     bool
+	includeLabels  map[string]bool
+	excludeLabels  map[string]bool
+	targetBranches []string
+	// excludeAuthors stores the lower-cased usernames.
+	excludeAuthors map[string]bool
+}
+
+func newMRFilter(c config.MergeRequestFilterConfig) (*mrFilter, error) {
+	for _, glob := range c.TargetBranches {
+		if _, err := path.Match(glob, ""); err != nil {
+			return nil, errors.Wrapf(err, "incorrect 'targetBranches' pattern: '%s'", glob)
+		}
+	}
+
+	return &mrFilter{
+		skipDrafts:     c.SkipDrafts,
+		includeLabels:  toSet(c.Labels.Include, false),
+		excludeLabels:  toSet(c.Labels.Exclude, false),
+		targetBranches: c.TargetBranches,
+		excludeAuthors: toSet(c.ExcludeAuthors, true),
+	}, nil
+}
+
+func toSet(values []string, lower bool) map[string]bool {
+	result := make(map[string]bool, len(values))
+
+	for _, v := range values {
+		if lower {
+			v = strings.ToLower(v)
+		}
+
+		result[v] = true
+	}
+
+	return result
+}
+
+// Match reports whether the MR passes the filter.
+func (f *mrFilter) Match(m *gitlab.MergeRequest) bool {
+	if f.skipDrafts && isDraft(m) {
+		return false
+	}
+
+	if m.Author != nil && f.excludeAuthors[strings.ToLower(m.Author.Username)] {
+		return false
+	}
+
+	if len(f.targetBranches) > 0 && !matchAny(f.targetBranches, m.TargetBranch) {
+		return false
+	}
+
+	included := len(f.includeLabels) == 0
+
+	for _, l := range m.Labels {
+		if f.excludeLabels[l] {
+			return false
+		}
+
+		if f.includeLabels[l] {
+			included = true
+		}
+	}
+
+	return included
+}
+
+// Filter returns the MRs passing the filter.
+func (f *mrFilter) Filter(mrs []*gitlab.MergeRequest) []*gitlab.MergeRequest {
+	result := make([]*gitlab.MergeRequest, 0, len(mrs))
+
+	for _, m := range mrs {
+		if f.Match(m) {
+			result = append(result, m)
+		}
+	}
+
+	return result
+}
+
+func isDraft(m *gitlab.MergeRequest) bool {
+	if m.WorkInProgress {
+		return true
+	}
+
+	title := strings.ToLower(strings.TrimSpace(m.Title))
+
+	for _, p := range draftPrefixes {
+		if strings.HasPrefix(title, p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchAny(globs []string, s string) bool {
+	for _, glob := range globs {
+		if ok, _ := path.Match(glob, s); ok {
+			return true
+		}
+	}
+
+	return false
+}
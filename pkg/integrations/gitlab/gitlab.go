@@ -17,8 +17,7 @@ limitations under the License.
 package gitlab
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
 	"log"
 	"strconv"
@@ -27,12 +26,16 @@ import (
 
 	"github.com/Dentrax/remind-us/pkg/config"
 	"github.com/Dentrax/remind-us/pkg/integrations"
+	"github.com/Dentrax/remind-us/pkg/reminder"
 	"github.com/hako/durafmt"
 	"github.com/pkg/errors"
-	"github.com/slack-go/slack"
 	"github.com/xanzy/go-gitlab"
+	"golang.org/x/sync/errgroup"
 )
 
+// perPage is the maximum page size allowed by GitLab API.
+const perPage = 100
+
 var errLoaded = errors.New("gitlab is not loaded")
 
 type GitLab struct {
@@ -40,9 +43,42 @@ type GitLab struct {
 
 	Result []*GroupScanResponse
 	config *config.GitLabIntegrationConfig
+
+	// filter filters the projects of the groups and the
+	// listed ones, it is generated by Validate function.
+	filter *projectFilter
+
+	// mrFilter filters the MRs of the projects,
+	// it is generated by Validate function.
+	mrFilter *mrFilter
+
+	// areas stores the areas to scan in each project.
+	//
+	// map: K: Area, V: DueWithin for the milestones
+	areas map[Area]time.Duration
+
+	// mode is how the MRs are grouped.
+	mode Mode
+
+	// sectionOrder and itemOrder store how the
+	// reminder is sorted, by the order config.
+	sectionOrder reminder.SectionOrder
+	itemOrder    reminder.ItemOrder
+
+	// staleness stores the thresholds of the MRs.
+	//
+	// map: K: Group ID, 0 for the global ones, V: staleness
+	staleness map[int]staleness
+
+	// emails stores the public emails of the users,
+	// only if ResolveEmails is enabled.
+	//
+	// map: K: User ID, V: Public Email
+	emails map[int]string
 }
 
 type GroupScanResponse struct {
+	// GroupID is 0 for the projects listened directly.
 	GroupID  int
 	Projects []GroupProjectScanResponse
 }
@@ -50,6 +86,17 @@ type GroupScanResponse struct {
 type GroupProjectScanResponse struct {
 	Project *gitlab.Project
 	MRs     []*gitlab.MergeRequest
+	// MRApprovals and MRPipelines store the approvals and the head
+	// pipeline of the MRs, the ones that could not be fetched are missing.
+	//
+	// map: K: MR IID
+	MRApprovals map[int]*gitlab.MergeRequestApprovals
+	MRPipelines map[int]*gitlab.PipelineInfo
+	Issues      []*gitlab.Issue
+	Milestones  []*gitlab.Milestone
+	// Pipeline is the latest pipeline of the
+	// default branch, only if it is failed.
+	Pipeline *gitlab.PipelineInfo
 }
 
 func (g *GitLab) Name() string {
@@ -71,199 +118,673 @@ func (g *GitLab) Enabled(config config.Integrations) bool {
 }
 
 func (g *GitLab) Validate(config config.Integrations) error {
+	filter, err := newProjectFilter(config.GitLab.Listen)
+	if err != nil {
+		return err
+	}
+
+	areas, err := parseAreas(config.GitLab.Listen.Areas)
+	if err != nil {
+		return err
+	}
+
+	mrFilter, err := newMRFilter(config.GitLab.MergeRequests)
+	if err != nil {
+		return err
+	}
+
+	mode, err := parseMode(config.GitLab.Mode)
+	if err != nil {
+		return err
+	}
+
+	staleness, err := parseStaleness(config.GitLab.Staleness)
+	if err != nil {
+		return err
+	}
+
+	sectionOrder, err := reminder.ParseSectionOrder(config.GitLab.Order.Sections)
+	if err != nil {
+		return errors.Wrap(err, "incorrect 'order'")
+	}
+
+	itemOrder, err := reminder.ParseItemOrder(config.GitLab.Order.Items)
+	if err != nil {
+		return errors.Wrap(err, "incorrect 'order'")
+	}
+
+	g.filter = filter
+	g.mrFilter = mrFilter
+	g.sectionOrder = sectionOrder
+	g.itemOrder = itemOrder
+	g.mode = mode
+	g.areas = areas
+	g.staleness = staleness
 	g.Validated = true
 
 	return nil
 }
 
 func (g *GitLab) Load(config config.Integrations) error {
-	git, err := gitlab.NewClient(config.GitLab.Token, gitlab.WithBaseURL(config.GitLab.BaseURL))
+	git, counter, err := newClient(config.GitLab)
 	if err != nil {
 		return errors.Wrap(err, "Unable to generate GitLab Client")
 	}
 
-	g.Result = make([]*GroupScanResponse, len(config.GitLab.Listen.Groups))
+	start := time.Now()
 
-	for i, l := range config.GitLab.Listen.Groups {
-		projects, _, err := git.Groups.ListGroupProjects(l, &gitlab.ListGroupProjectsOptions{
-			ListOptions: gitlab.ListOptions{
-				Page:    1,
-				PerPage: 100,
-			},
-		})
+	defer func() {
+		log.Printf("%d GitLab API call(s) made in %s\n", counter.Calls(), time.Since(start).Round(time.Millisecond))
+	}()
+
+	listen := config.GitLab.Listen
+
+	g.config = config.GitLab
+	g.Result = make([]*GroupScanResponse, 0, len(listen.Groups)+1)
+
+	// A project can be listed by multiple groups, i.e. with subgroups
+	seen := make(map[int]bool)
+
+	for _, l := range listen.Groups {
+		projects, pages, err := listGroupProjects(git, l, listen)
 		if err != nil {
 			return errors.Wrapf(err, "Unable to list projects for group id: '%d'", l)
 		}
 
-		log.Printf("%d project(s) found in group %d\n", len(projects), l)
+		filtered := make([]*gitlab.Project, 0, len(projects))
 
-		g.Result[i] = &GroupScanResponse{
-			GroupID:  l,
-			Projects: make([]GroupProjectScanResponse, len(projects)),
+		for _, p := range projects {
+			if seen[p.ID] || !g.filter.Match(p) {
+				continue
+			}
+
+			seen[p.ID] = true
+			filtered = append(filtered, p)
 		}
 
-		stateType := "opened"
-
-		for j, p := range projects {
-			mrs, _, err := git.MergeRequests.ListProjectMergeRequests(p.ID,
-				&gitlab.ListProjectMergeRequestsOptions{
-					ListOptions: gitlab.ListOptions{
-						Page:    1,
-						PerPage: 100,
-					},
-					State: &stateType,
-				},
-			)
+		log.Printf("%d project(s) found in group %d, %d filtered out, %d page(s) fetched\n", len(projects), l, len(projects)-len(filtered), pages)
+
+		scans, err := g.scanProjects(git, filtered)
+		if err != nil {
+			return errors.Wrapf(err, "Unable to scan projects for group id: %d", l)
+		}
+
+		g.Result = append(g.Result, &GroupScanResponse{
+			GroupID:  l,
+			Projects: scans,
+		})
+	}
+
+	if len(listen.Projects) > 0 {
+		projects := make([]*gitlab.Project, 0, len(listen.Projects))
+
+		for _, pid := range listen.Projects {
+			p, _, err := git.Projects.GetProject(pid, nil)
 			if err != nil {
-				return errors.Wrapf(err, "Unable to list merge requests for project id: %d, group id: %d", p.ID, l)
+				return errors.Wrapf(err, "Unable to get project: '%s'", pid)
 			}
 
-			log.Printf("%d MR(s) found in project %s\n", len(mrs), p.Name)
-
-			g.Result[i].Projects[j] = GroupProjectScanResponse{
-				Project: p,
-				MRs:     mrs,
+			if seen[p.ID] || !g.filter.Match(p) {
+				continue
 			}
+
+			seen[p.ID] = true
+			projects = append(projects, p)
+		}
+
+		log.Printf("%d project(s) listed, %d filtered out\n", len(listen.Projects), len(listen.Projects)-len(projects))
+
+		scans, err := g.scanProjects(git, projects)
+		if err != nil {
+			return err
 		}
+
+		g.Result = append(g.Result, &GroupScanResponse{
+			Projects: scans,
+		})
+	}
+
+	if config.GitLab.ResolveEmails {
+		g.emails = resolveEmails(git, g.Result)
 	}
 
-	g.config = config.GitLab
 	g.Loaded = true
 
 	return nil
 }
 
-func (g *GitLab) GenerateSlackMessage(options integrations.GenerateMessageOptions) (*slack.WebhookMessage, error) {
+// scanProjects scans the areas of the given projects concurrently,
+// by the configured number of workers. The order is kept.
+func (g *GitLab) scanProjects(git *gitlab.Client, projects []*gitlab.Project) ([]GroupProjectScanResponse, error) {
+	result := make([]GroupProjectScanResponse, len(projects))
+
+	workers := g.config.Workers
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+
+	eg, ctx := errgroup.WithContext(context.Background())
+	sem := make(chan struct{}, workers)
+
+	for i, p := range projects {
+		i, p := i, p
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return nil, eg.Wait()
+		}
+
+		eg.Go(func() error {
+			defer func() { <-sem }()
+
+			scan, err := g.scanProject(git, p)
+			if err != nil {
+				return err
+			}
+
+			result[i] = scan
+
+			return nil
+		})
+	}
+
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// scanProject scans the areas of the project.
+func (g *GitLab) scanProject(git *gitlab.Client, p *gitlab.Project) (GroupProjectScanResponse, error) {
+	result := GroupProjectScanResponse{
+		Project: p,
+	}
+
+	if _, ok := g.areas[AreaMR]; ok {
+		mrs, pages, err := listProjectMergeRequests(git, p.ID)
+		if err != nil {
+			return result, errors.Wrapf(err, "Unable to list merge requests for project id: %d", p.ID)
+		}
+
+		found := len(mrs)
+
+		if g.mrFilter != nil {
+			mrs = g.mrFilter.Filter(mrs)
+		}
+
+		log.Printf("%d MR(s) found in project %s, %d filtered out, %d page(s) fetched\n", found, p.Name, found-len(mrs), pages)
+
+		result.MRs = mrs
+		result.MRApprovals, result.MRPipelines = getMRDetails(git, p.ID, mrs)
+	}
+
+	if _, ok := g.areas[AreaIssue]; ok {
+		issues, pages, err := listProjectIssues(git, p.ID)
+		if err != nil {
+			return result, errors.Wrapf(err, "Unable to list issues for project id: %d", p.ID)
+		}
+
+		log.Printf("%d issue(s) found in project %s, %d page(s) fetched\n", len(issues), p.Name, pages)
+
+		result.Issues = issues
+	}
+
+	if _, ok := g.areas[AreaPipeline]; ok {
+		pipeline, err := getFailedPipeline(git, p)
+		if err != nil {
+			return result, errors.Wrapf(err, "Unable to list pipelines for project id: %d", p.ID)
+		}
+
+		result.Pipeline = pipeline
+	}
+
+	if dueWithin, ok := g.areas[AreaMilestone]; ok {
+		milestones, pages, err := listDueMilestones(git, p.ID, dueWithin)
+		if err != nil {
+			return result, errors.Wrapf(err, "Unable to list milestones for project id: %d", p.ID)
+		}
+
+		log.Printf("%d due milestone(s) found in project %s, %d page(s) fetched\n", len(milestones), p.Name, pages)
+
+		result.Milestones = milestones
+	}
+
+	return result, nil
+}
+
+// getMRDetails fetches the approvals and the head pipeline of each MR.
+// They are optional, i.e. approvals are not available in every GitLab
+// tier, so the failures are logged and skipped.
+func getMRDetails(git *gitlab.Client, pid int, mrs []*gitlab.MergeRequest) (map[int]*gitlab.MergeRequestApprovals, map[int]*gitlab.PipelineInfo) {
+	approvals := make(map[int]*gitlab.MergeRequestApprovals, len(mrs))
+	pipelines := make(map[int]*gitlab.PipelineInfo, len(mrs))
+
+	for _, m := range mrs {
+		a, _, err := git.MergeRequestApprovals.GetConfiguration(pid, m.IID)
+		if err != nil {
+			log.Printf("unable to get approvals of MR !%d for project id: %d: %v\n", m.IID, pid, err)
+		} else {
+			approvals[m.IID] = a
+		}
+
+		ps, _, err := git.MergeRequests.ListMergeRequestPipelines(pid, m.IID)
+		if err != nil {
+			log.Printf("unable to list pipelines of MR !%d for project id: %d: %v\n", m.IID, pid, err)
+		} else if len(ps) > 0 {
+			// Pipelines are ordered by the newest first
+			pipelines[m.IID] = ps[0]
+		}
+	}
+
+	return approvals, pipelines
+}
+
+// walkPages calls the list func until the last page or the done func, if
+// any, returns true, by setting the page to the next one on each call.
+// Returns the number of pages fetched.
+func walkPages(page *int, done func() bool, list func() (*gitlab.Response, error)) (int, error) {
+	pages := 0
+
+	for {
+		resp, err := list()
+		if err != nil {
+			return pages, err
+		}
+
+		pages++
+
+		if resp.NextPage == 0 || (done != nil && done()) {
+			return pages, nil
+		}
+
+		*page = resp.NextPage
+	}
+}
+
+// listGroupProjects walks the pages of the group projects until the last
+// one or the MaxProjects, if it is positive. Returns the number of pages fetched.
+func listGroupProjects(git *gitlab.Client, gid int, listen config.IntegrationListenConfig) ([]*gitlab.Project, int, error) {
+	var result []*gitlab.Project
+
+	max := listen.MaxProjects
+
+	opt := &gitlab.ListGroupProjectsOptions{
+		ListOptions: gitlab.ListOptions{
+			Page:    1,
+			PerPage: perPage,
+		},
+		IncludeSubgroups: gitlab.Bool(listen.IncludeSubgroups),
+	}
+
+	// not to fetch more than needed
+	if max > 0 && max < perPage {
+		opt.PerPage = max
+	}
+
+	if listen.SkipArchived {
+		opt.Archived = gitlab.Bool(false)
+	}
+
+	done := func() bool {
+		return max > 0 && len(result) >= max
+	}
+
+	pages, err := walkPages(&opt.Page, done, func() (*gitlab.Response, error) {
+		projects, resp, err := git.Groups.ListGroupProjects(gid, opt)
+		result = append(result, projects...)
+
+		return resp, err
+	})
+
+	if done() {
+		result = result[:max]
+	}
+
+	return result, pages, err
+}
+
+// listProjectMergeRequests walks all the pages of the opened MRs of
+// the project. Returns the number of pages fetched.
+func listProjectMergeRequests(git *gitlab.Client, pid int) ([]*gitlab.MergeRequest, int, error) {
+	var result []*gitlab.MergeRequest
+
+	stateType := "opened"
+
+	opt := &gitlab.ListProjectMergeRequestsOptions{
+		ListOptions: gitlab.ListOptions{
+			Page:    1,
+			PerPage: perPage,
+		},
+		State: &stateType,
+	}
+
+	pages, err := walkPages(&opt.Page, nil, func() (*gitlab.Response, error) {
+		mrs, resp, err := git.MergeRequests.ListProjectMergeRequests(pid, opt)
+		result = append(result, mrs...)
+
+		return resp, err
+	})
+
+	return result, pages, err
+}
+
+func (g *GitLab) GenerateReminder(options integrations.GenerateMessageOptions) (*reminder.Reminder, error) {
 	if !g.Loaded {
 		return nil, errLoaded
 	}
 
-	var attachments []slack.Attachment
+	var sections []reminder.Section
 
-	for _, r := range g.Result {
-		for _, p := range r.Projects {
-			var resultProject bytes.Buffer
+	generators := []func(GroupProjectScanResponse, staleness) (reminder.Section, bool){
+		g.generateIssueSection,
+		g.generatePipelineSection,
+		g.generateMilestoneSection,
+	}
 
-			oldest := time.Now()
-			openMRs := 0
+	// MRs are grouped by project unless it is the digest mode only
+	if g.mode != ModeDigest {
+		generators = append([]func(GroupProjectScanResponse, staleness) (reminder.Section, bool){g.generateMRSection}, generators...)
+	}
 
-			for _, m := range p.MRs {
-				if strings.EqualFold(m.State, "opened") {
-					openMRs++
+	for _, r := range g.Result {
+		st := g.stalenessOf(r.GroupID)
 
-					if m.CreatedAt != nil && m.CreatedAt.Before(oldest) {
-						oldest = *m.CreatedAt
-					}
+		for _, p := range r.Projects {
+			for _, generate := range generators {
+				if s, ok := generate(p, st); ok {
+					s.Limit = g.config.MaxItemsPerProject
+					sections = append(sections, s)
 				}
 			}
+		}
+	}
 
-			if openMRs <= 0 {
-				continue
-			}
+	if g.mode == ModeDigest || g.mode == ModeBoth {
+		sections = append(sections, g.generateDigestSections()...)
+	}
 
-			GetTimeText := func(t *time.Time) string {
-				d := durafmt.Parse(time.Since(*t)).LimitFirstN(1)
-				if d.Duration().Hours() >= 48 {
-					return fmt.Sprintf("*%s*", d.String())
-				}
+	rem := &reminder.Reminder{
+		Source:   g.Name(),
+		Sections: sections,
+	}
 
-				return d.String()
-			}
+	rem.Sort(g.sectionOrder, g.itemOrder)
+	rem.Truncate(g.config.MaxItems)
 
-			GetMRKeyword := func(link string, openMRs int) string {
-				if openMRs > 1 {
-					return fmt.Sprintf("are <%s|%d open MRs>", link, openMRs)
-				}
+	return rem, nil
+}
 
-				return fmt.Sprintf("is <%s|%d open MR>", link, openMRs)
-			}(fmt.Sprintf("%s/merge_requests?state=opened", p.Project.WebURL), openMRs)
+func (g *GitLab) generateMRSection(p GroupProjectScanResponse, st staleness) (reminder.Section, bool) {
+	oldest := time.Now()
+	openMRs := 0
 
-			resultProject.WriteString(fmt.Sprintf("There %s in <%s|%s>.", GetMRKeyword, p.Project.WebURL, p.Project.NameWithNamespace))
+	for _, m := range p.MRs {
+		if strings.EqualFold(m.State, "opened") {
+			openMRs++
 
-			if openMRs > 1 {
-				resultProject.WriteString(fmt.Sprintf(" The oldest one is %s old.", GetTimeText(&oldest)))
+			if m.CreatedAt != nil && m.CreatedAt.Before(oldest) {
+				oldest = *m.CreatedAt
 			}
+		}
+	}
 
-			resultProject.WriteString("\n")
+	if openMRs <= 0 {
+		return reminder.Section{}, false
+	}
 
-			var reviewedMRs []*gitlab.MergeRequest
+	mrsLink := fmt.Sprintf("%s/merge_requests?state=opened", p.Project.WebURL)
 
-			var awaitingMRs []*gitlab.MergeRequest
+	GetMRKeyword := func(link string, openMRs int) reminder.Text {
+		if openMRs > 1 {
+			return reminder.Text{reminder.Plain("are "), reminder.Link(fmt.Sprintf("%d open MRs", openMRs), link)}
+		}
 
-			for _, m := range p.MRs {
-				if strings.EqualFold(m.State, "opened") {
-					if m.Upvotes > 0 || m.Downvotes > 0 || m.UserNotesCount > 0 {
-						reviewedMRs = append(reviewedMRs, m)
-					} else {
-						awaitingMRs = append(awaitingMRs, m)
-					}
-				}
-			}
+		return reminder.Text{reminder.Plain("is "), reminder.Link(fmt.Sprintf("%d open MR", openMRs), link)}
+	}(mrsLink, openMRs)
 
-			GetDateInfo := func(created, updated *time.Time) string {
-				if created != nil && updated != nil {
-					if created.Equal(*updated) {
-						return fmt.Sprintf("(created %s ago)", GetTimeText(created))
-					}
+	summary := reminder.Text{reminder.Plain("There ")}
+	summary = append(summary, GetMRKeyword...)
+	summary = append(summary,
+		reminder.Plain(" in "),
+		reminder.Link(p.Project.NameWithNamespace, p.Project.WebURL),
+		reminder.Plain("."),
+	)
 
-					return fmt.Sprintf("(created %s ago, updated %s ago)", GetTimeText(created), GetTimeText(updated))
-				} else if created != nil {
-					return fmt.Sprintf("(created %s ago)", GetTimeText(created))
-				}
+	if openMRs > 1 {
+		summary = append(summary, reminder.Plain(" The oldest one is "), getTimeText(&oldest, st.boldAfter()), reminder.Plain(" old."))
+	}
 
-				return ""
-			}
+	var reviewedMRs []*gitlab.MergeRequest
 
-			AppendMRInfo := func(buffer *bytes.Buffer, m *gitlab.MergeRequest) {
-				GetCanBeMerged := func(blockingDiscussion, hasConflicts bool) rune {
-					if blockingDiscussion || hasConflicts {
-						return '✘'
-					}
+	var awaitingMRs []*gitlab.MergeRequest
 
-					return '✓'
-				}(!m.BlockingDiscussionsResolved, m.HasConflicts)
-				buffer.WriteString(fmt.Sprintf("\n%c <%s|%s> %s", GetCanBeMerged, m.WebURL, m.Title, GetDateInfo(m.CreatedAt, m.UpdatedAt)))
-				buffer.WriteString(fmt.Sprintf(" by <@%s>", m.Author.Username))
+	for _, m := range p.MRs {
+		if strings.EqualFold(m.State, "opened") {
+			if isReviewed(m) {
+				reviewedMRs = append(reviewedMRs, m)
+			} else {
+				awaitingMRs = append(awaitingMRs, m)
 			}
+		}
+	}
 
-			if len(reviewedMRs) > 1 {
-				resultProject.WriteString(fmt.Sprintf("\n%d MRs are reviewed and waiting:", len(reviewedMRs)))
-			} else if len(reviewedMRs) == 1 {
-				resultProject.WriteString("\n1 MR is reviewed and waiting:")
-			}
+	GetMRItems := func(mrs []*gitlab.MergeRequest) []reminder.Item {
+		items := make([]reminder.Item, len(mrs))
 
-			for _, m := range reviewedMRs {
-				AppendMRInfo(&resultProject, m)
-			}
+		for i, m := range mrs {
+			items[i] = g.newMRItem(p, m, st)
+		}
 
-			if len(reviewedMRs) >= 1 {
-				resultProject.WriteString("\n")
-			}
+		return items
+	}
 
-			if len(awaitingMRs) > 1 {
-				resultProject.WriteString(fmt.Sprintf("\n%d MRs are awaiting review:", len(awaitingMRs)))
-			} else if len(awaitingMRs) == 1 {
-				resultProject.WriteString("\n1 MR is awaiting review:")
-			}
+	var groups []reminder.Group
 
-			for _, m := range awaitingMRs {
-				AppendMRInfo(&resultProject, m)
-			}
+	if len(reviewedMRs) > 0 {
+		reviewed := reminder.NewGroup(GetMRItems(reviewedMRs), func(n int) string {
+			return fmt.Sprintf("%s %s reviewed and waiting", countText(n, "MR", "MRs"), isAre(n))
+		})
+		reviewed.Break = true
+
+		groups = append(groups, reviewed)
+	}
+
+	if len(awaitingMRs) > 0 {
+		groups = append(groups, reminder.NewGroup(GetMRItems(awaitingMRs), func(n int) string {
+			return fmt.Sprintf("%s %s awaiting review", countText(n, "MR", "MRs"), isAre(n))
+		}))
+	}
+
+	s := g.newProjectSection(p.Project)
+
+	s.Summary = summary
+	s.Groups = groups
+	s.MoreLink = mrsLink
+
+	st.apply(&s, time.Since(oldest))
+
+	return s, true
+}
+
+// isReviewed reports whether the MR is reviewed and waiting,
+// otherwise it is awaiting review.
+func isReviewed(m *gitlab.MergeRequest) bool {
+	return m.Upvotes > 0 || m.Downvotes > 0 || m.UserNotesCount > 0
+}
+
+// newMRItem returns the item of the MR with its dates and readiness.
+func (g *GitLab) newMRItem(p GroupProjectScanResponse, m *gitlab.MergeRequest, st staleness) reminder.Item {
+	readiness, blocked := getReadiness(p.MRApprovals[m.IID], p.MRPipelines[m.IID])
+
+	GetCanBeMerged := func(blockingDiscussion, hasConflicts bool) reminder.Status {
+		if blockingDiscussion || hasConflicts || blocked {
+			return reminder.StatusFailed
+		}
+
+		return reminder.StatusOK
+	}(!m.BlockingDiscussionsResolved, m.HasConflicts)
+
+	return reminder.Item{
+		Status:    GetCanBeMerged,
+		Title:     m.Title,
+		Link:      m.WebURL,
+		Details:   append(getDateInfo(m.CreatedAt, m.UpdatedAt, st), readiness...),
+		Time:      m.CreatedAt,
+		Score:     m.Upvotes - m.Downvotes,
+		Author:    g.newUser(m.Author),
+		Assignees: g.newUsers(m.Assignees),
+		Reviewers: g.newUsers(m.Reviewers),
+	}
+}
+
+// getDateInfo returns when the MR is created and updated, i.e. "(created 2 days ago)".
+func getDateInfo(created, updated *time.Time, st staleness) reminder.Text {
+	if created != nil && updated != nil {
+		if created.Equal(*updated) {
+			return reminder.Text{reminder.Plain("(created "), getTimeText(created, st.boldAfter()), reminder.Plain(" ago)")}
+		}
+
+		return reminder.Text{
+			reminder.Plain("(created "), getTimeText(created, st.boldAfter()),
+			reminder.Plain(" ago, updated "), getTimeText(updated, st.boldAfter()), reminder.Plain(" ago)"),
+		}
+	} else if created != nil {
+		return reminder.Text{reminder.Plain("(created "), getTimeText(created, st.boldAfter()), reminder.Plain(" ago)")}
+	}
+
+	return nil
+}
+
+// getReadiness returns the approvals and the pipeline status, i.e.
+// " [1/2 approvals, pipeline *failed*]", and whether they block the MR.
+func getReadiness(approvals *gitlab.MergeRequestApprovals, pipeline *gitlab.PipelineInfo) (reminder.Text, bool) {
+	var parts []reminder.Text
+
+	blocked := false
+
+	if approvals != nil {
+		approved := len(approvals.ApprovedBy)
+
+		if approvals.ApprovalsRequired > 0 {
+			parts = append(parts, reminder.Text{reminder.Plain(fmt.Sprintf("%d/%d approvals", approved, approvals.ApprovalsRequired))})
+		} else {
+			parts = append(parts, reminder.Text{reminder.Plain(countText(approved, "approval", "approvals"))})
+		}
+
+		blocked = approvals.ApprovalsLeft > 0
+	}
+
+	if pipeline != nil {
+		status := pipelineStatus(pipeline.Status)
+
+		if pipeline.Status == "failed" {
+			parts = append(parts, reminder.Text{reminder.Plain("pipeline "), reminder.Bold(status)})
+			blocked = true
+		} else {
+			parts = append(parts, reminder.Text{reminder.Plain(fmt.Sprintf("pipeline %s", status))})
+		}
+	}
+
+	if len(parts) == 0 {
+		return nil, false
+	}
+
+	result := reminder.Text{reminder.Plain(" [")}
+
+	for i, p := range parts {
+		if i > 0 {
+			result = append(result, reminder.Plain(", "))
+		}
+
+		result = append(result, p...)
+	}
 
-			attachments = append(attachments, slack.Attachment{
-				Color:      "good",
-				AuthorName: p.Project.Name,
-				AuthorLink: p.Project.HTTPURLToRepo,
-				AuthorIcon: p.Project.AvatarURL,
-				Text:       resultProject.String(),
-				Footer:     p.Project.Namespace.FullPath,
-				FooterIcon: fmt.Sprintf("%s%s", g.config.BaseURL, p.Project.Namespace.AvatarURL),
-				Ts:         json.Number(strconv.FormatInt(time.Now().Unix(), 10)),
-			})
+	return append(result, reminder.Plain("]")), blocked
+}
+
+// pipelineStatus returns the human-readable pipeline status.
+func pipelineStatus(status string) string {
+	switch status {
+	case "success":
+		return "passed"
+	case "created", "waiting_for_resource", "preparing", "pending", "scheduled":
+		return "pending"
+	default:
+		return status
+	}
+}
+
+// getTimeText returns the duration since the given time,
+// it is bold if it is older than the given duration.
+func getTimeText(t *time.Time, boldAfter time.Duration) reminder.Span {
+	d := durafmt.Parse(time.Since(*t)).LimitFirstN(1)
+	if d.Duration() >= boldAfter {
+		return reminder.Bold(d.String())
+	}
+
+	return reminder.Plain(d.String())
+}
+
+func (g *GitLab) newUser(u *gitlab.BasicUser) *reminder.User {
+	if u == nil {
+		return nil
+	}
+
+	return &reminder.User{
+		Username: u.Username,
+		Name:     u.Name,
+		Email:    g.emails[u.ID],
+	}
+}
+
+func (g *GitLab) newUsers(us []*gitlab.BasicUser) []reminder.User {
+	result := make([]reminder.User, 0, len(us))
+
+	for _, u := range us {
+		if u != nil {
+			result = append(result, *g.newUser(u))
 		}
 	}
 
-	return &slack.WebhookMessage{
-		Attachments: attachments,
-	}, nil
+	return result
+}
+
+// resolveEmails fetches the public emails of the users of the MRs. The
+// users without a public email or that could not be fetched are skipped.
+func resolveEmails(git *gitlab.Client, result []*GroupScanResponse) map[int]string {
+	emails := make(map[int]string)
+	seen := make(map[int]bool)
+
+	for _, r := range result {
+		for _, p := range r.Projects {
+			for _, m := range p.MRs {
+				users := append([]*gitlab.BasicUser{m.Author}, m.Assignees...)
+				users = append(users, m.Reviewers...)
+
+				for _, u := range users {
+					if u == nil || seen[u.ID] {
+						continue
+					}
+
+					seen[u.ID] = true
+
+					user, _, err := git.Users.GetUser(u.ID)
+					if err != nil {
+						log.Printf("unable to get user: '%s': %v\n", u.Username, err)
+						continue
+					}
+
+					if user.PublicEmail != "" {
+						emails[u.ID] = user.PublicEmail
+					}
+				}
+			}
+		}
+	}
+
+	log.Printf("%d public email(s) resolved for %d user(s)\n", len(emails), len(seen))
+
+	return emails
 }
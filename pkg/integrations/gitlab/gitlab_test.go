@@ -20,12 +20,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"bou.ke/monkey"
+	slackalerter "github.com/Dentrax/remind-us/pkg/alerters/slack"
 	"github.com/Dentrax/remind-us/pkg/config"
 	"github.com/Dentrax/remind-us/pkg/integrations"
+	"github.com/Dentrax/remind-us/pkg/reminder"
 	"github.com/pkg/errors"
 	"github.com/slack-go/slack"
 	"github.com/stretchr/testify/assert"
@@ -80,12 +87,12 @@ func loadGitlab(rootPath string, groupPaths []string) (*GitLab, error) {
 	}
 
 	return &GitLab{
-		integrations.Integration{
+		Integration: integrations.Integration{
 			Validated: true,
 			Loaded:    true,
 		},
-		groupScanResponses,
-		&config.GitLabIntegrationConfig{
+		Result: groupScanResponses,
+		config: &config.GitLabIntegrationConfig{
 			BaseURL: "http://gitlab.com",
 		},
 	}, nil
@@ -201,14 +208,16 @@ func TestGitLab_GenerateMessage(t *testing.T) {
 			assert.NoError(t, err)
 			assert.NotNil(t, g)
 
-			got, err := g.GenerateSlackMessage(tt.options)
+			r, err := g.GenerateReminder(tt.options)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("GenerateMessage() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("GenerateReminder() error = %v, wantErr %v", err, tt.wantErr)
 
 				return
 			}
 
-			assert.NotNil(t, got)
+			assert.NotNil(t, r)
+
+			got := slackalerter.Render(r)
 
 			assert.Len(t, got.Attachments, len(tt.want.Attachments))
 
@@ -227,6 +236,94 @@ func TestGitLab_GenerateMessage(t *testing.T) {
 	}
 }
 
+// TestGitLab_GenerateMessage_Groups keeps the Slack texts of the MR
+// groups byte-identical to the ones before the reminders. It is not
+// parallel, since unpatching time.Now would affect the other tests.
+func TestGitLab_GenerateMessage_Groups(t *testing.T) {
+	patch := monkey.Patch(time.Now, func() time.Time { return time.Date(2020, time.December, 13, 7, 7, 7, 7, time.UTC) })
+
+	t.Cleanup(func() {
+		patch.Unpatch()
+	})
+
+	created := time.Date(2020, time.December, 10, 7, 7, 7, 7, time.UTC)
+	updated := time.Date(2020, time.December, 13, 6, 7, 7, 7, time.UTC)
+
+	newMR := func(iid int, reviewed bool) *gitlab.MergeRequest {
+		m := &gitlab.MergeRequest{
+			IID:                         iid,
+			State:                       "opened",
+			Title:                       fmt.Sprintf("MR %d", iid),
+			WebURL:                      fmt.Sprintf("https://gitlab.com/foo/baz/-/merge_requests/%d", iid),
+			CreatedAt:                   &created,
+			UpdatedAt:                   &updated,
+			BlockingDiscussionsResolved: true,
+			Author:                      &gitlab.BasicUser{Username: "dentrax"},
+		}
+
+		if reviewed {
+			m.UserNotesCount = 1
+		}
+
+		return m
+	}
+
+	tests := []struct {
+		name string
+		mrs  []*gitlab.MergeRequest
+		want string
+	}{
+		{
+			"it should end the reviewed MRs by a line break",
+			[]*gitlab.MergeRequest{newMR(1, true)},
+			`There is <https://gitlab.com/foo/baz/merge_requests?state=opened|1 open MR> in <https://gitlab.com/foo/baz|foo / baz>.
+
+1 MR is reviewed and waiting:
+✓ <https://gitlab.com/foo/baz/-/merge_requests/1|MR 1> (created *3 days* ago, updated 1 hour ago) by <@dentrax>
+`,
+		},
+		{
+			"it should not end the awaiting MRs by a line break",
+			[]*gitlab.MergeRequest{newMR(1, false), newMR(2, false)},
+			`There are <https://gitlab.com/foo/baz/merge_requests?state=opened|2 open MRs> in <https://gitlab.com/foo/baz|foo / baz>. The oldest one is *3 days* old.
+
+2 MRs are awaiting review:
+✓ <https://gitlab.com/foo/baz/-/merge_requests/1|MR 1> (created *3 days* ago, updated 1 hour ago) by <@dentrax>
+✓ <https://gitlab.com/foo/baz/-/merge_requests/2|MR 2> (created *3 days* ago, updated 1 hour ago) by <@dentrax>`,
+		},
+		{
+			"it should separate the reviewed and the awaiting MRs by an empty line",
+			[]*gitlab.MergeRequest{newMR(1, false), newMR(2, true)},
+			`There are <https://gitlab.com/foo/baz/merge_requests?state=opened|2 open MRs> in <https://gitlab.com/foo/baz|foo / baz>. The oldest one is *3 days* old.
+
+1 MR is reviewed and waiting:
+✓ <https://gitlab.com/foo/baz/-/merge_requests/2|MR 2> (created *3 days* ago, updated 1 hour ago) by <@dentrax>
+
+1 MR is awaiting review:
+✓ <https://gitlab.com/foo/baz/-/merge_requests/1|MR 1> (created *3 days* ago, updated 1 hour ago) by <@dentrax>`,
+		},
+	}
+
+	for _, tt := range tests {
+		g := &GitLab{
+			Integration: integrations.Integration{Validated: true, Loaded: true},
+			Result: []*GroupScanResponse{{Projects: []GroupProjectScanResponse{{
+				Project: &gitlab.Project{Name: "baz", NameWithNamespace: "foo / baz", WebURL: "https://gitlab.com/foo/baz"},
+				MRs:     tt.mrs,
+			}}}},
+			config: &config.GitLabIntegrationConfig{BaseURL: "http://gitlab.com"},
+		}
+
+		r, err := g.GenerateReminder(integrations.GenerateMessageOptions{})
+		assert.NoError(t, err, tt.name)
+
+		got := slackalerter.Render(r)
+
+		assert.Len(t, got.Attachments, 1, tt.name)
+		assert.Equal(t, tt.want, got.Attachments[0].Text, tt.name)
+	}
+}
+
 func Benchmark_GenerateMessage(b *testing.B) {
 	g, _ := loadGitlab("../../../testdata/integrations/gitlab", []string{
 		"../../../testdata/integrations/gitlab/groups_0_projects.json",
@@ -241,6 +338,672 @@ func Benchmark_GenerateMessage(b *testing.B) {
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		_, _ = g.GenerateSlackMessage(o)
+		_, _ = g.GenerateReminder(o)
+	}
+}
+
+func newGitLabServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	pages := map[string][]string{
+		"/api/v4/groups/111/projects": {
+			`[{"id": 1, "name": "foo", "path_with_namespace": "g/foo"}, {"id": 2, "name": "bar", "path_with_namespace": "g/sub/bar", "archived": true}]`,
+			`[{"id": 3, "name": "baz", "path_with_namespace": "g/baz"}]`,
+		},
+		"/api/v4/projects/g/qux":            {`{"id": 4, "name": "qux", "path_with_namespace": "g/qux"}`},
+		"/api/v4/projects/1":                {`{"id": 1, "name": "foo", "path_with_namespace": "g/foo"}`},
+		"/api/v4/projects/g/mirror":         {`{"id": 5, "name": "mirror", "path_with_namespace": "g/mirror", "mirror": true}`},
+		"/api/v4/projects/4/merge_requests": {`[{"id": 40, "iid": 1, "state": "opened"}]`},
+		"/api/v4/projects/1/merge_requests": {
+			`[{"id": 10, "iid": 1, "state": "opened"}]`,
+			`[{"id": 11, "iid": 2, "state": "opened"}]`,
+		},
+		"/api/v4/projects/2/merge_requests": {`[]`},
+		"/api/v4/projects/3/merge_requests": {`[{"id": 30, "iid": 1, "state": "opened"}]`},
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, ok := pages[r.URL.Path]
+		if !ok {
+			switch {
+			case strings.HasSuffix(r.URL.Path, "/approvals"):
+				p = []string{`{"approvals_required": 2, "approvals_left": 1, "approved_by": [{"user": {"username": "foo"}}]}`}
+			case strings.HasSuffix(r.URL.Path, "/pipelines"):
+				p = []string{`[{"id": 2, "status": "success"}, {"id": 1, "status": "failed"}]`}
+			default:
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+		}
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page < 1 || page > len(p) {
+			page = 1
+		}
+
+		if page < len(p) {
+			w.Header().Set("X-Next-Page", strconv.Itoa(page+1))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(p[page-1]))
+	}))
+}
+
+func TestGitLab_Load(t *testing.T) {
+	t.Parallel()
+
+	ts := newGitLabServer(t)
+
+	t.Cleanup(ts.Close)
+
+	tests := []struct {
+		name   string
+		listen config.IntegrationListenConfig
+		want   map[int]int
+	}{
+		{
+			"it should walk all pages",
+			config.IntegrationListenConfig{
+				Groups: []int{111},
+			},
+			map[int]int{1: 2, 2: 0, 3: 1},
+		},
+		{
+			"it should stop at max projects",
+			config.IntegrationListenConfig{
+				Groups:      []int{111},
+				MaxProjects: 2,
+			},
+			map[int]int{1: 2, 2: 0},
+		},
+		{
+			"it should cut the page at max projects",
+			config.IntegrationListenConfig{
+				Groups:      []int{111},
+				MaxProjects: 1,
+			},
+			map[int]int{1: 2},
+		},
+		{
+			"it should skip archived and excluded projects",
+			config.IntegrationListenConfig{
+				Groups:       []int{111},
+				SkipArchived: true,
+				Exclude: config.ProjectFilterConfig{
+					Paths: []string{"g/b*"},
+				},
+			},
+			map[int]int{1: 2},
+		},
+		{
+			"it should only scan included projects",
+			config.IntegrationListenConfig{
+				Groups: []int{111},
+				Include: config.ProjectFilterConfig{
+					Regexes: []string{"^g/sub/"},
+				},
+			},
+			map[int]int{2: 0},
+		},
+		{
+			"it should scan the listed projects once",
+			config.IntegrationListenConfig{
+				Groups:      []int{111},
+				Projects:    []string{"g/qux", "1"},
+				MaxProjects: 2,
+			},
+			map[int]int{1: 2, 2: 0, 4: 1},
+		},
+		{
+			"it should filter the listed projects",
+			config.IntegrationListenConfig{
+				Projects:    []string{"g/qux", "g/mirror", "1"},
+				SkipMirrors: true,
+				Exclude: config.ProjectFilterConfig{
+					Paths: []string{"g/q*"},
+				},
+			},
+			map[int]int{1: 2},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			g := &GitLab{}
+
+			c := config.Integrations{
+				GitLab: &config.GitLabIntegrationConfig{
+					BaseURL: ts.URL,
+					Listen:  tt.listen,
+				},
+			}
+
+			assert.NoError(t, g.Validate(c))
+			assert.NoError(t, g.Load(c))
+
+			got := make(map[int]int)
+
+			for _, r := range g.Result {
+				for _, p := range r.Projects {
+					got[p.Project.ID] = len(p.MRs)
+
+					assert.Len(t, p.MRApprovals, len(p.MRs))
+					assert.Len(t, p.MRPipelines, len(p.MRs))
+
+					for _, m := range p.MRs {
+						assert.Equal(t, 1, p.MRApprovals[m.IID].ApprovalsLeft)
+						assert.Equal(t, "success", p.MRPipelines[m.IID].Status)
+					}
+				}
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGitLab_GenerateReminder_Areas(t *testing.T) {
+	t.Parallel()
+
+	due := gitlab.ISOTime(time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC))
+
+	project := &gitlab.Project{
+		Name:              "baz",
+		NameWithNamespace: "Foo / Bar / baz",
+		WebURL:            "https://gitlab.com/foo/bar/baz",
+	}
+
+	g := &GitLab{
+		Integration: integrations.Integration{
+			Validated: true,
+			Loaded:    true,
+		},
+		Result: []*GroupScanResponse{
+			{
+				Projects: []GroupProjectScanResponse{
+					{
+						Project: project,
+						Issues: []*gitlab.Issue{
+							{Title: "Overdue", WebURL: "https://gitlab.com/foo/bar/baz/-/issues/1", DueDate: &due, Assignees: []*gitlab.IssueAssignee{{Username: "foo"}}},
+							{Title: "Unassigned", WebURL: "https://gitlab.com/foo/bar/baz/-/issues/2"},
+							{Title: "Assigned", WebURL: "https://gitlab.com/foo/bar/baz/-/issues/3", Assignees: []*gitlab.IssueAssignee{{Username: "foo"}}},
+						},
+						Pipeline: &gitlab.PipelineInfo{ID: 42, Ref: "main", Status: "failed", WebURL: "https://gitlab.com/foo/bar/baz/-/pipelines/42"},
+						Milestones: []*gitlab.Milestone{
+							{Title: "v1.0.0", WebURL: "https://gitlab.com/foo/bar/baz/-/milestones/1", DueDate: &due},
+						},
+					},
+				},
+			},
+		},
+		config: &config.GitLabIntegrationConfig{
+			BaseURL: "http://gitlab.com",
+		},
+	}
+
+	r, err := g.GenerateReminder(integrations.GenerateMessageOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, r.Sections, 3)
+
+	got := slackalerter.Render(r)
+
+	assert.Equal(t, "warning", got.Attachments[0].Color)
+	assert.Regexp(t, `^There are <https://gitlab.com/foo/bar/baz/issues\?state=opened\|2 open issues> needing attention in <https://gitlab.com/foo/bar/baz\|Foo / Bar / baz>.
+
+1 issue is past due:
+✘ <https://gitlab.com/foo/bar/baz/-/issues/1\|Overdue> \(due \*\d+ years\* ago\)
+
+1 issue is unassigned:
+• <https://gitlab.com/foo/bar/baz/-/issues/2\|Unassigned> $`, got.Attachments[0].Text)
+
+	assert.Equal(t, "danger", got.Attachments[1].Color)
+	assert.Equal(t, "The latest pipeline of <https://gitlab.com/foo/bar/baz/-/pipelines?ref=main|main> has failed in <https://gitlab.com/foo/bar/baz|Foo / Bar / baz>.\n", got.Attachments[1].Text)
+	assert.Equal(t, "✘ <https://gitlab.com/foo/bar/baz/-/pipelines/42|Pipeline #42> ", got.Attachments[1].Fields[0].Value)
+
+	assert.Equal(t, "warning", got.Attachments[2].Color)
+	assert.Len(t, got.Attachments[2].Fields, 1)
+}
+
+func TestGitLab_GenerateReminder_MRDetails(t *testing.T) {
+	t.Parallel()
+
+	g, err := loadGitlab("../../../testdata/integrations/gitlab", []string{
+		"../../../testdata/integrations/gitlab/groups_3_projects.json",
+	})
+	assert.NoError(t, err)
+
+	p := &g.Result[0].Projects[0]
+
+	p.MRApprovals = map[int]*gitlab.MergeRequestApprovals{
+		p.MRs[0].IID: {ApprovalsRequired: 2, ApprovalsLeft: 1, ApprovedBy: []*gitlab.MergeRequestApproverUser{{}}},
+		p.MRs[1].IID: {ApprovedBy: []*gitlab.MergeRequestApproverUser{{}}},
+	}
+	p.MRPipelines = map[int]*gitlab.PipelineInfo{
+		p.MRs[0].IID: {Status: "success"},
+		p.MRs[1].IID: {Status: "failed"},
+		p.MRs[3].IID: {Status: "running"},
+	}
+
+	r, err := g.GenerateReminder(integrations.GenerateMessageOptions{})
+	assert.NoError(t, err)
+
+	var items []reminder.Item
+
+	for _, group := range r.Sections[0].Groups {
+		items = append(items, group.Items...)
+	}
+
+	got := make(map[string]string)
+	status := make(map[string]reminder.Status)
+
+	for _, item := range items {
+		got[item.Title] = item.Details.String()
+		status[item.Title] = item.Status
+	}
+
+	assert.Regexp(t, `\) \[1/2 approvals, pipeline passed\]$`, got[p.MRs[0].Title])
+	assert.Regexp(t, `\) \[1 approval, pipeline failed\]$`, got[p.MRs[1].Title])
+	assert.Regexp(t, `\) \[pipeline running\]$`, got[p.MRs[3].Title])
+
+	assert.Equal(t, reminder.StatusFailed, status[p.MRs[0].Title])
+	assert.Equal(t, reminder.StatusFailed, status[p.MRs[1].Title])
+}
+
+func TestGitLab_GenerateReminder_Staleness(t *testing.T) {
+	t.Parallel()
+
+	created := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	newResult := func(gid int) []*GroupScanResponse {
+		return []*GroupScanResponse{
+			{
+				GroupID: gid,
+				Projects: []GroupProjectScanResponse{
+					{
+						Project: &gitlab.Project{Name: "baz", NameWithNamespace: "Foo / Bar / baz", WebURL: "https://gitlab.com/foo/bar/baz"},
+						MRs: []*gitlab.MergeRequest{
+							{Title: "Stale", State: "opened", WebURL: "https://gitlab.com/foo/bar/baz/-/merge_requests/1", CreatedAt: &created},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		gid          int
+		staleness    config.StalenessConfig
+		wantSeverity reminder.Severity
+		wantPrefix   string
+		wantChannel  string
+		wantMentions []reminder.User
+	}{
+		{
+			name:         "it should not mark if not configured",
+			staleness:    config.StalenessConfig{},
+			wantSeverity: reminder.SeverityOK,
+			wantPrefix:   "There is",
+		},
+		{
+			name:         "it should not mark if it is not old enough",
+			staleness:    config.StalenessConfig{Warn: "1000000h"},
+			wantSeverity: reminder.SeverityOK,
+			wantPrefix:   "There is",
+		},
+		{
+			name:         "it should warn",
+			staleness:    config.StalenessConfig{Warn: "48h"},
+			wantSeverity: reminder.SeverityWarning,
+			wantPrefix:   "⚠️ There is",
+		},
+		{
+			name:         "it should escalate",
+			staleness:    config.StalenessConfig{Warn: "48h", Escalate: "72h", Mention: "S0TEAM", Channel: "#escalations"},
+			wantSeverity: reminder.SeverityCritical,
+			wantPrefix:   "🚨 There is",
+			wantChannel:  "#escalations",
+			wantMentions: []reminder.User{{Username: "S0TEAM", Group: true}},
+		},
+		{
+			name: "it should override by the group",
+			gid:  42,
+			staleness: config.StalenessConfig{
+				Warn:   "48h",
+				Groups: []config.GroupStalenessConfig{{ID: 42, Escalate: "72h"}},
+			},
+			wantSeverity: reminder.SeverityCritical,
+			wantPrefix:   "🚨 There is",
+		},
+		{
+			name: "it should not override the other groups",
+			gid:  7,
+			staleness: config.StalenessConfig{
+				Warn:   "48h",
+				Groups: []config.GroupStalenessConfig{{ID: 42, Escalate: "72h"}},
+			},
+			wantSeverity: reminder.SeverityWarning,
+			wantPrefix:   "⚠️ There is",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			g := &GitLab{
+				Integration: integrations.Integration{
+					Validated: true,
+					Loaded:    true,
+				},
+				Result: newResult(tt.gid),
+				config: &config.GitLabIntegrationConfig{BaseURL: "http://gitlab.com"},
+			}
+
+			staleness, err := parseStaleness(tt.staleness)
+			assert.NoError(t, err)
+
+			g.staleness = staleness
+
+			r, err := g.GenerateReminder(integrations.GenerateMessageOptions{})
+			assert.NoError(t, err)
+			assert.Len(t, r.Sections, 1)
+
+			s := r.Sections[0]
+
+			assert.Equal(t, tt.wantSeverity, s.Severity)
+			assert.True(t, strings.HasPrefix(s.Summary.String(), tt.wantPrefix), s.Summary.String())
+			assert.Equal(t, tt.wantChannel, s.Channel)
+			assert.Equal(t, tt.wantMentions, s.Mentions)
+		})
+	}
+}
+
+func TestParseStaleness_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for _, c := range []config.StalenessConfig{
+		{Warn: "foo"},
+		{Escalate: "-1h"},
+		{Warn: "72h", Escalate: "48h"},
+		{Groups: []config.GroupStalenessConfig{{ID: 0}}},
+		{Warn: "72h", Groups: []config.GroupStalenessConfig{{ID: 1, Escalate: "48h"}}},
+	} {
+		_, err := parseStaleness(c)
+		assert.Error(t, err, "%+v", c)
+	}
+}
+
+func TestMRFilter_Match(t *testing.T) {
+	t.Parallel()
+
+	newMR := func(title, target, author string, labels ...string) *gitlab.MergeRequest {
+		return &gitlab.MergeRequest{
+			Title:        title,
+			TargetBranch: target,
+			Author:       &gitlab.BasicUser{Username: author},
+			Labels:       labels,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		config config.MergeRequestFilterConfig
+		mr     *gitlab.MergeRequest
+		want   bool
+	}{
+		{"it should match all if empty", config.MergeRequestFilterConfig{}, newMR("Draft: foo", "main", "foo"), true},
+		{"it should skip the drafts", config.MergeRequestFilterConfig{SkipDrafts: true}, newMR("Draft: foo", "main", "foo"), false},
+		{"it should skip the WIPs", config.MergeRequestFilterConfig{SkipDrafts: true}, &gitlab.MergeRequest{Title: "foo", WorkInProgress: true}, false},
+		{"it should match the non-drafts", config.MergeRequestFilterConfig{SkipDrafts: true}, newMR("Drafting foo", "main", "foo"), true},
+		{"it should skip the excluded authors", config.MergeRequestFilterConfig{ExcludeAuthors: []string{"Renovate-Bot"}}, newMR("Update bar", "main", "renovate-bot"), false},
+		{"it should match the target branches", config.MergeRequestFilterConfig{TargetBranches: []string{"release/*"}}, newMR("foo", "release/v1", "foo"), true},
+		{"it should skip the other target branches", config.MergeRequestFilterConfig{TargetBranches: []string{"main"}}, newMR("foo", "develop", "foo"), false},
+		{"it should match the included labels", config.MergeRequestFilterConfig{Labels: config.LabelFilterConfig{Include: []string{"backend"}}}, newMR("foo", "main", "foo", "bug", "backend"), true},
+		{"it should skip without the included labels", config.MergeRequestFilterConfig{Labels: config.LabelFilterConfig{Include: []string{"backend"}}}, newMR("foo", "main", "foo", "bug"), false},
+		{"it should skip the excluded labels", config.MergeRequestFilterConfig{Labels: config.LabelFilterConfig{Include: []string{"backend"}, Exclude: []string{"dependencies"}}}, newMR("foo", "main", "foo", "backend", "dependencies"), false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			f, err := newMRFilter(tt.config)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, f.Match(tt.mr))
+		})
+	}
+
+	_, err := newMRFilter(config.MergeRequestFilterConfig{TargetBranches: []string{"["}})
+	assert.Error(t, err)
+}
+
+func TestGitLab_GenerateReminder_Digest(t *testing.T) {
+	t.Parallel()
+
+	created := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	g := &GitLab{
+		Integration: integrations.Integration{
+			Validated: true,
+			Loaded:    true,
+		},
+		Result: []*GroupScanResponse{
+			{
+				Projects: []GroupProjectScanResponse{
+					{
+						Project: &gitlab.Project{Name: "baz", NameWithNamespace: "Foo / Bar / baz", WebURL: "https://gitlab.com/foo/bar/baz"},
+						MRs: []*gitlab.MergeRequest{
+							{
+								Title: "Awaiting", State: "opened", CreatedAt: &created,
+								Author:    &gitlab.BasicUser{Username: "foo"},
+								Reviewers: []*gitlab.BasicUser{{Username: "bar"}},
+								Assignees: []*gitlab.BasicUser{{Username: "foo"}, {Username: "bar"}},
+							},
+							{
+								Title: "Reviewed", State: "opened", CreatedAt: &created, UserNotesCount: 1, HasConflicts: true,
+								Author:    &gitlab.BasicUser{Username: "foo"},
+								Reviewers: []*gitlab.BasicUser{{Username: "baz"}},
+							},
+							{
+								Title: "Merged", State: "merged", CreatedAt: &created,
+								Author:    &gitlab.BasicUser{Username: "qux"},
+								Reviewers: []*gitlab.BasicUser{{Username: "bar"}},
+							},
+						},
+					},
+				},
+			},
+		},
+		config: &config.GitLabIntegrationConfig{BaseURL: "http://gitlab.com"},
+	}
+
+	mode, err := parseMode("Digest")
+	assert.NoError(t, err)
+
+	g.mode = mode
+
+	r, err := g.GenerateReminder(integrations.GenerateMessageOptions{})
+	assert.NoError(t, err)
+
+	summaries := make(map[string]string)
+
+	for _, s := range r.Sections {
+		if assert.NotNil(t, s.Recipient) {
+			summaries[s.Recipient.Username] = s.Summary.String()
+		}
+	}
+
+	assert.Equal(t, map[string]string{
+		"foo": "1 of your MRs has conflicts.",
+		"bar": "1 MR waiting for your review.",
+		"baz": "1 MR reviewed and waiting.",
+	}, summaries)
+
+	// in order of appearance
+	assert.Equal(t, "bar", r.Sections[0].Recipient.Username)
+	assert.Equal(t, "baz: Awaiting", r.Sections[0].Groups[0].Items[0].Title)
+
+	_, err = parseMode("foo")
+	assert.Error(t, err)
+}
+
+func TestRateLimitWait(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		header   http.Header
+		want     time.Duration
+		wantFind bool
+	}{
+		{"it should not find without headers", http.Header{}, 0, false},
+		{"it should parse Retry-After in seconds", http.Header{"Retry-After": {"3"}}, 3 * time.Second, true},
+		{"it should parse Retry-After as HTTP date", http.Header{"Retry-After": {now.Add(time.Minute).Format(http.TimeFormat)}}, time.Minute, true},
+		{"it should parse RateLimit-Reset", http.Header{"Ratelimit-Reset": {strconv.FormatInt(now.Add(10*time.Second).Unix(), 10)}}, 10 * time.Second, true},
+		{"it should not wait for the past", http.Header{"Ratelimit-Reset": {strconv.FormatInt(now.Add(-time.Hour).Unix(), 10)}}, 0, true},
+		{"it should bound the wait", http.Header{"Retry-After": {"86400"}}, maxRateLimitWait, true},
+		{"it should not find the incorrect ones", http.Header{"Retry-After": {"foo"}}, 0, false},
 	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := rateLimitWait(tt.header, now)
+			assert.Equal(t, tt.wantFind, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	assert.Equal(t, minBackoff, backoff(0, 0, 0, nil))
+	assert.Equal(t, 4*minBackoff, backoff(0, 0, 2, nil))
+	assert.Equal(t, maxBackoff, backoff(0, 0, 100, nil))
+	assert.Equal(t, 3*time.Second, backoff(0, 0, 5, &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"3"}}}))
+
+	// GitLab sends the RateLimit-Reset on each response, only the 429s wait for it
+	reset := http.Header{"Ratelimit-Reset": {strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)}}
+	assert.Equal(t, 2*minBackoff, backoff(0, 0, 1, &http.Response{StatusCode: http.StatusBadGateway, Header: reset}))
+}
+
+func TestPacer(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	header := func(limit, remaining int, reset time.Duration) http.Header {
+		return http.Header{
+			"Ratelimit-Limit":     {strconv.Itoa(limit)},
+			"Ratelimit-Remaining": {strconv.Itoa(remaining)},
+			"Ratelimit-Reset":     {strconv.FormatInt(now.Add(reset).Unix(), 10)},
+		}
+	}
+
+	p := &pacer{}
+
+	p.observe(header(600, 500, time.Minute), now)
+	assert.Equal(t, time.Duration(0), p.reserve(now), "it should not pace with plenty of requests left")
+
+	p.observe(header(600, 9, time.Minute), now)
+	assert.Equal(t, 6*time.Second, p.interval, "it should spread the remaining requests until the reset")
+
+	assert.Equal(t, time.Duration(0), p.reserve(now))
+	assert.Equal(t, 6*time.Second, p.reserve(now), "it should pace the concurrent requests together")
+	assert.Equal(t, 12*time.Second, p.reserve(now))
+
+	p.observe(http.Header{}, now)
+	assert.Equal(t, 6*time.Second, p.interval, "it should ignore the responses without the headers")
+
+	p.observe(header(600, 600, time.Minute), now)
+	assert.Equal(t, time.Duration(0), p.interval, "it should stop pacing after the reset")
+}
+
+func TestGitLab_Load_RateLimited(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		// the first call is rate limited
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`[]`))
+	}))
+
+	t.Cleanup(ts.Close)
+
+	c := config.Integrations{
+		GitLab: &config.GitLabIntegrationConfig{
+			BaseURL: ts.URL,
+			Listen:  config.IntegrationListenConfig{Groups: []int{1}},
+		},
+	}
+
+	g := &GitLab{}
+
+	assert.NoError(t, g.Validate(c))
+	assert.NoError(t, g.Load(c))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestGitLab_GenerateReminder_MaxItems(t *testing.T) {
+	t.Parallel()
+
+	created := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	var mrs []*gitlab.MergeRequest
+
+	for i := 1; i <= 3; i++ {
+		mrs = append(mrs, &gitlab.MergeRequest{
+			Title:     fmt.Sprintf("MR %d", i),
+			State:     "opened",
+			WebURL:    fmt.Sprintf("https://gitlab.com/foo/baz/-/merge_requests/%d", i),
+			CreatedAt: &created,
+		})
+	}
+
+	g := &GitLab{
+		Integration: integrations.Integration{
+			Validated: true,
+			Loaded:    true,
+		},
+		Result: []*GroupScanResponse{
+			{
+				Projects: []GroupProjectScanResponse{
+					{
+						Project: &gitlab.Project{Name: "baz", NameWithNamespace: "Foo / baz", WebURL: "https://gitlab.com/foo/baz"},
+						MRs:     mrs,
+					},
+				},
+			},
+		},
+		config: &config.GitLabIntegrationConfig{BaseURL: "http://gitlab.com", MaxItemsPerProject: 2},
+	}
+
+	staleness, err := parseStaleness(config.StalenessConfig{})
+	assert.NoError(t, err)
+
+	g.staleness = staleness
+
+	r, err := g.GenerateReminder(integrations.GenerateMessageOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, r.Sections, 1)
+
+	s := r.Sections[0]
+
+	assert.Len(t, s.Groups[0].Items, 2)
+	assert.Equal(t, reminder.Text{reminder.Link("…and 1 more", "https://gitlab.com/foo/baz/merge_requests?state=opened")}, s.Overflow())
+	assert.Contains(t, s.Summary.String(), "3 open MRs")
 }
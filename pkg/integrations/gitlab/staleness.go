@@ -0,0 +1,138 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitlab
+
+import (
+	"time"
+
+	"github.com/Dentrax/remind-us/pkg/config"
+	"github.com/Dentrax/remind-us/pkg/reminder"
+	"github.com/pkg/errors"
+)
+
+// defaultBoldAfter is the default age that gets bold,
+// if the warn threshold is not configured.
+const defaultBoldAfter = 48 * time.Hour
+
+// staleness stores the parsed thresholds of a group,
+// a zero threshold means it is disabled.
+type staleness struct {
+	warn     time.Duration
+	escalate time.Duration
+	mention  string
+	channel  string
+}
+
+// boldAfter returns the age that gets bold in the texts.
+func (s staleness) boldAfter() time.Duration {
+	if s.warn > 0 {
+		return s.warn
+	}
+
+	return defaultBoldAfter
+}
+
+// apply marks the section by the age of the oldest MR, escalation takes
+// precedence over the warning. The summary is prefixed by an emoji.
+func (s staleness) apply(section *reminder.Section, age time.Duration) {
+	switch {
+	case s.escalate > 0 && age >= s.escalate:
+		section.Severity = reminder.SeverityCritical
+		section.Summary = append(reminder.Text{reminder.Plain("🚨 ")}, section.Summary...)
+		section.Channel = s.channel
+
+		if s.mention != "" {
+			section.Mentions = []reminder.User{{Username: s.mention, Group: true}}
+		}
+	case s.warn > 0 && age >= s.warn:
+		section.Severity = reminder.SeverityWarning
+		section.Summary = append(reminder.Text{reminder.Plain("⚠️ ")}, section.Summary...)
+	}
+}
+
+// parseStaleness parses the global thresholds and the overrides of
+// the groups, the global ones are stored by the group ID 0.
+func parseStaleness(c config.StalenessConfig) (map[int]staleness, error) {
+	global, err := newStaleness(staleness{}, c.Warn, c.Escalate, c.Mention, c.Channel)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[int]staleness{0: global}
+
+	for _, gc := range c.Groups {
+		if gc.ID <= 0 {
+			return nil, errors.Errorf("incorrect staleness group ID: '%d'", gc.ID)
+		}
+
+		s, err := newStaleness(global, gc.Warn, gc.Escalate, gc.Mention, gc.Channel)
+		if err != nil {
+			return nil, errors.Wrapf(err, "incorrect staleness of group '%d'", gc.ID)
+		}
+
+		result[gc.ID] = s
+	}
+
+	return result, nil
+}
+
+// newStaleness overrides the parent by the non-empty fields.
+func newStaleness(parent staleness, warn, escalate, mention, channel string) (staleness, error) {
+	s := parent
+
+	if warn != "" {
+		d, err := time.ParseDuration(warn)
+		if err != nil || d <= 0 {
+			return s, errors.Errorf("incorrect 'warn' pattern: '%s'", warn)
+		}
+
+		s.warn = d
+	}
+
+	if escalate != "" {
+		d, err := time.ParseDuration(escalate)
+		if err != nil || d <= 0 {
+			return s, errors.Errorf("incorrect 'escalate' pattern: '%s'", escalate)
+		}
+
+		s.escalate = d
+	}
+
+	if s.warn > 0 && s.escalate > 0 && s.escalate < s.warn {
+		return s, errors.Errorf("'escalate' must not be less than 'warn': '%s' < '%s'", s.escalate, s.warn)
+	}
+
+	if mention != "" {
+		s.mention = mention
+	}
+
+	if channel != "" {
+		s.channel = channel
+	}
+
+	return s, nil
+}
+
+// stalenessOf returns the thresholds of the group,
+// falls back to the global ones.
+func (g *GitLab) stalenessOf(gid int) staleness {
+	if s, ok := g.staleness[gid]; ok {
+		return s
+	}
+
+	return g.staleness[0]
+}
@@ -18,7 +18,7 @@ package integrations
 
 import (
 	"github.com/Dentrax/remind-us/pkg/config"
-	"github.com/slack-go/slack"
+	"github.com/Dentrax/remind-us/pkg/reminder"
 )
 
 type Integration struct {
@@ -31,10 +31,17 @@ type IIntegration interface {
 	Enabled(config.Integrations) bool
 	Validate(config.Integrations) error
 	Load(config.Integrations) error
-	GenerateSlackMessage(GenerateMessageOptions) (*slack.WebhookMessage, error)
+	GenerateReminder(GenerateMessageOptions) (*reminder.Reminder, error)
+}
+
+// ICommitter is implemented by the integrations that should
+// persist what they have generated, once it gets alerted.
+type ICommitter interface {
+	Commit() error
 }
 
 type GenerateMessageOptions struct {
-	// For integration name, i.e. Slack.
+	// For is the name of the alerter that the
+	// reminder is generated for, i.e. Slack.
 	For string
 }
@@ -0,0 +1,360 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rss
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/pkg/errors"
+)
+
+// expression is a compiled boolean expression over the fields of an item:
+//
+//	expr       := and ("or" and)*
+//	and        := unary ("and" unary)*
+//	unary      := "not" unary | "(" expr ")" | comparison
+//	comparison := field op string
+//	field      := "title" | "description" | "category" | "author"
+//	op         := "~" | "!~" | "==" | "!=" | "contains"
+//
+// "~" matches by a RegExp, "==" and "contains" are case-insensitive.
+// Since a field can have multiple values, i.e. the categories, a
+// comparison holds if any of the values holds, where "!~" and "!="
+// hold if none of them does. Strings are quoted by either " or '.
+type expression interface {
+	eval(i *gofeed.Item) bool
+}
+
+type andExpr struct {
+	left, right expression
+}
+
+func (e andExpr) eval(i *gofeed.Item) bool {
+	return e.left.eval(i) && e.right.eval(i)
+}
+
+type orExpr struct {
+	left, right expression
+}
+
+func (e orExpr) eval(i *gofeed.Item) bool {
+	return e.left.eval(i) || e.right.eval(i)
+}
+
+type notExpr struct {
+	expr expression
+}
+
+func (e notExpr) eval(i *gofeed.Item) bool {
+	return !e.expr.eval(i)
+}
+
+type comparison struct {
+	field  field
+	negate bool
+	match  func(string) bool
+}
+
+func (e comparison) eval(i *gofeed.Item) bool {
+	matched := false
+
+	for _, v := range e.field.values(i) {
+		if e.match(v) {
+			matched = true
+			break
+		}
+	}
+
+	return matched != e.negate
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenOp
+	tokenLParen
+	tokenRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of expression"
+	}
+
+	if t.kind == tokenString {
+		return fmt.Sprintf("%q at position %d", t.text, t.pos+1)
+	}
+
+	return fmt.Sprintf("'%s' at position %d", t.text, t.pos+1)
+}
+
+// lex splits the expression into the tokens.
+func lex(s string) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(s); {
+		c := s[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{tokenLParen, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{tokenRParen, ")", i})
+			i++
+		case c == '~':
+			tokens = append(tokens, token{tokenOp, "~", i})
+			i++
+		case c == '!' || c == '=':
+			if i+1 < len(s) && (s[i+1] == '~' || s[i+1] == '=') && !(c == '=' && s[i+1] == '~') {
+				tokens = append(tokens, token{tokenOp, s[i : i+2], i})
+				i += 2
+
+				continue
+			}
+
+			return nil, errors.Errorf("unexpected '%c' at position %d", c, i+1)
+		case c == '"' || c == '\'':
+			var b strings.Builder
+
+			start := i
+
+			for i++; ; i++ {
+				if i >= len(s) {
+					return nil, errors.Errorf("unterminated string at position %d", start+1)
+				}
+
+				if s[i] == '\\' && i+1 < len(s) && (s[i+1] == c || s[i+1] == '\\') {
+					i++
+					b.WriteByte(s[i])
+
+					continue
+				}
+
+				if s[i] == c {
+					i++
+					break
+				}
+
+				b.WriteByte(s[i])
+			}
+
+			tokens = append(tokens, token{tokenString, b.String(), start})
+		case unicode.IsLetter(rune(c)):
+			start := i
+
+			for i < len(s) && (unicode.IsLetter(rune(s[i])) || s[i] == '_') {
+				i++
+			}
+
+			tokens = append(tokens, token{tokenIdent, s[start:i], start})
+		default:
+			return nil, errors.Errorf("unexpected '%c' at position %d", c, i+1)
+		}
+	}
+
+	return append(tokens, token{tokenEOF, "", len(s)}), nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+
+	return t
+}
+
+func (p *parser) keyword(k string) bool {
+	t := p.peek()
+
+	if t.kind == tokenIdent && strings.EqualFold(t.text, k) {
+		p.pos++
+
+		return true
+	}
+
+	return false
+}
+
+func (p *parser) parseOr() (expression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.keyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = orExpr{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (expression, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.keyword("and") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		left = andExpr{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseUnary() (expression, error) {
+	if p.keyword("not") {
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return notExpr{e}, nil
+	}
+
+	if p.peek().kind == tokenLParen {
+		p.next()
+
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if t := p.next(); t.kind != tokenRParen {
+			return nil, errors.Errorf("expected ')', got %s", t)
+		}
+
+		return e, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (expression, error) {
+	t := p.next()
+	if t.kind != tokenIdent {
+		return nil, errors.Errorf("expected a field, got %s", t)
+	}
+
+	var f field
+
+	switch strings.ToLower(t.text) {
+	case "title":
+		f = fieldTitle
+	case "description":
+		f = fieldDescription
+	case "category", "categories":
+		f = fieldCategory
+	case "author":
+		f = fieldAuthor
+	default:
+		return nil, errors.Errorf("unknown field %s", t)
+	}
+
+	op := p.next()
+	if op.kind != tokenOp && !(op.kind == tokenIdent && strings.EqualFold(op.text, "contains")) {
+		return nil, errors.Errorf("expected an operator, got %s", op)
+	}
+
+	v := p.next()
+	if v.kind != tokenString {
+		return nil, errors.Errorf("expected a quoted string, got %s", v)
+	}
+
+	c := comparison{field: f}
+
+	switch strings.ToLower(op.text) {
+	case "~", "!~":
+		re, err := regexp.Compile(v.text)
+		if err != nil {
+			return nil, errors.Wrapf(err, "incorrect RegExp pattern at position %d", v.pos+1)
+		}
+
+		c.match = re.MatchString
+	case "==", "!=":
+		c.match = func(s string) bool {
+			return strings.EqualFold(s, v.text)
+		}
+	case "contains":
+		lower := strings.ToLower(v.text)
+		c.match = func(s string) bool {
+			return strings.Contains(strings.ToLower(s), lower)
+		}
+	}
+
+	c.negate = strings.HasPrefix(op.text, "!")
+
+	return c, nil
+}
+
+// compileExpression compiles the expression, the errors
+// point to the position of the bad part of it.
+func compileExpression(s string) (expression, error) {
+	tokens, err := lex(s)
+	if err != nil {
+		return nil, errors.Wrapf(err, "incorrect expression: '%s'", s)
+	}
+
+	p := &parser{tokens: tokens}
+
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, errors.Wrapf(err, "incorrect expression: '%s'", s)
+	}
+
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, errors.Errorf("incorrect expression: '%s': unexpected %s", s, t)
+	}
+
+	return e, nil
+}
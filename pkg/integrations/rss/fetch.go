@@ -0,0 +1,233 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rss
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Dentrax/remind-us/pkg/config"
+	"github.com/mmcdole/gofeed"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultTimeout = 30 * time.Second
+
+	// defaultRetryWait is multiplied by the attempt number.
+	defaultRetryWait = time.Second
+
+	// defaultUserAgent is sent unless it is configured, some sources
+	// (i.e. Reddit) rate limit the generic ones harder.
+	defaultUserAgent = "remind-us/1.0 (+https://github.com/Dentrax/remind-us)"
+)
+
+// fetchPolicy is how a source is fetched.
+type fetchPolicy struct {
+	// timeout is per attempt.
+	timeout time.Duration
+	// retries is the number of the attempts after the first one.
+	retries int
+	wait    time.Duration
+
+	userAgent string
+	// headers are sent in addition, i.e. the auth tokens.
+	headers map[string]string
+}
+
+// validators are the cache validators of a source, sent as the
+// conditional request headers not to download it again if not modified.
+type validators struct {
+	etag         string
+	lastModified string
+}
+
+func (v validators) empty() bool {
+	return v.etag == "" && v.lastModified == ""
+}
+
+// fetchResult is the outcome of fetching a source. Either the
+// feed or the err is set, unless the source is not modified.
+type fetchResult struct {
+	url         string
+	feed        *gofeed.Feed
+	notModified bool
+	validators  validators
+	err         error
+}
+
+// newFetchPolicy returns the policy of the source,
+// inheriting the defaults of the integration.
+func newFetchPolicy(c *config.RSSIntegrationConfig, source config.RSSSourceConfig) (fetchPolicy, error) {
+	p := fetchPolicy{
+		timeout:   defaultTimeout,
+		retries:   c.Retries,
+		wait:      defaultRetryWait,
+		userAgent: defaultUserAgent,
+		headers:   source.Headers,
+	}
+
+	if c.UserAgent != "" {
+		p.userAgent = c.UserAgent
+	}
+
+	for _, timeout := range []string{c.Timeout, source.Timeout} {
+		if timeout == "" {
+			continue
+		}
+
+		d, err := time.ParseDuration(timeout)
+		if err != nil || d <= 0 {
+			return p, errors.Errorf("incorrect 'timeout' pattern: '%s'", timeout)
+		}
+
+		p.timeout = d
+	}
+
+	if source.Retries != nil {
+		p.retries = *source.Retries
+	}
+
+	if p.retries < 0 {
+		return p, errors.Errorf("incorrect 'retries': '%d'", p.retries)
+	}
+
+	return p, nil
+}
+
+// fetchAll fetches the sources concurrently by the given validators,
+// if any. A failing source does not cancel the others, the results
+// are in the given order.
+func fetchAll(ctx context.Context, urls []string, policies map[string]fetchPolicy, cached map[string]validators) []fetchResult {
+	results := make([]fetchResult, len(urls))
+
+	var wg sync.WaitGroup
+
+	for i, u := range urls {
+		i, u := i, u
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			results[i] = fetch(ctx, u, policies[u], cached[u])
+		}()
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+// fetch fetches the source by retrying on failures.
+func fetch(ctx context.Context, u string, p fetchPolicy, v validators) fetchResult {
+	var result fetchResult
+
+	for attempt := 0; attempt <= p.retries; attempt++ {
+		if attempt > 0 {
+			log.Printf("retrying RSS source: '%s' (%d/%d): %v\n", u, attempt, p.retries, result.err)
+
+			select {
+			case <-time.After(p.wait * time.Duration(attempt)):
+			case <-ctx.Done():
+				return fetchResult{url: u, err: ctx.Err()}
+			}
+		}
+
+		result = fetchOnce(ctx, u, p, v)
+		if result.err == nil {
+			return result
+		}
+	}
+
+	return result
+}
+
+// fetchOnce sends a conditional request if there are validators, the body
+// is not parsed if it is not modified. Each attempt has its own parser,
+// since they are not thread-safe.
+func fetchOnce(ctx context.Context, u string, p fetchPolicy, v validators) fetchResult {
+	result := fetchResult{url: u}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		result.err = err
+		return result
+	}
+
+	req = req.WithContext(ctx)
+
+	// the headers of the source override the default User-Agent
+	req.Header.Set("User-Agent", p.userAgent)
+
+	for k, val := range p.headers {
+		req.Header.Set(k, val)
+	}
+
+	if v.etag != "" {
+		req.Header.Set("If-None-Match", v.etag)
+	}
+
+	if v.lastModified != "" {
+		req.Header.Set("If-Modified-Since", v.lastModified)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		result.err = err
+		return result
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		result.notModified = true
+		result.validators = v
+
+		return result
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		result.err = gofeed.HTTPError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+		}
+
+		return result
+	}
+
+	feed, err := gofeed.NewParser().Parse(resp.Body)
+	if err != nil {
+		result.err = err
+		return result
+	}
+
+	result.feed = feed
+	result.validators = validators{
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+	}
+
+	return result
+}
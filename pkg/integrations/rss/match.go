@@ -0,0 +1,198 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rss
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Dentrax/remind-us/pkg/config"
+	"github.com/mmcdole/gofeed"
+	"github.com/pkg/errors"
+)
+
+// field is a matchable part of an item.
+type field string
+
+const (
+	fieldTitle       field = "title"
+	fieldDescription field = "description"
+	fieldCategory    field = "category"
+	fieldAuthor      field = "author"
+)
+
+var (
+	htmlTagRegExp    = regexp.MustCompile(`(?s)<[^>]*>`)
+	whitespaceRegExp = regexp.MustCompile(`\s+`)
+)
+
+// values returns the values of the field of the item, the
+// description includes the content, both are HTML stripped.
+func (f field) values(i *gofeed.Item) []string {
+	switch f {
+	case fieldTitle:
+		return []string{i.Title}
+	case fieldDescription:
+		return []string{stripHTML(i.Description), stripHTML(i.Content)}
+	case fieldCategory:
+		return i.Categories
+	case fieldAuthor:
+		if i.Author == nil {
+			return nil
+		}
+
+		return []string{i.Author.Name, i.Author.Email}
+	default:
+		return nil
+	}
+}
+
+// stripHTML returns the text of the HTML, i.e. "<p>foo &amp; bar</p>" is "foo & bar".
+func stripHTML(s string) string {
+	s = htmlTagRegExp.ReplaceAllString(s, " ")
+	s = html.UnescapeString(s)
+
+	return strings.TrimSpace(whitespaceRegExp.ReplaceAllString(s, " "))
+}
+
+// fieldMatcher matches a field of the items if any of the values
+// contains any of the given strings, or matches any of the RegExps.
+type fieldMatcher struct {
+	field    field
+	contains []string
+	regexes  []*regexp.Regexp
+}
+
+func newFieldMatcher(f field, c config.RSSMatchConfig) (fieldMatcher, error) {
+	m := fieldMatcher{
+		field:    f,
+		contains: make([]string, len(c.Contains)),
+		regexes:  make([]*regexp.Regexp, len(c.Regexes)),
+	}
+
+	for i, s := range c.Contains {
+		// case-insensitive search
+		m.contains[i] = strings.ToLower(s)
+	}
+
+	for i, regex := range c.Regexes {
+		re, err := regexp.Compile(regex)
+		if err != nil {
+			return m, errors.Wrapf(err, "incorrect RegExp pattern of '%s': '%s'", f, regex)
+		}
+
+		m.regexes[i] = re
+	}
+
+	return m, nil
+}
+
+func (m fieldMatcher) empty() bool {
+	return len(m.contains) == 0 && len(m.regexes) == 0
+}
+
+func (m fieldMatcher) Match(i *gofeed.Item) bool {
+	for _, v := range m.field.values(i) {
+		if v == "" {
+			continue
+		}
+
+		lower := strings.ToLower(v)
+
+		for _, c := range m.contains {
+			if strings.Contains(lower, c) {
+				return true
+			}
+		}
+
+		for _, re := range m.regexes {
+			if re.MatchString(v) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+type fieldMatchConfig struct {
+	field  field
+	config config.RSSMatchConfig
+}
+
+// newFieldMatchers compiles the matchers of the source other than the
+// title, which is matched by the 'MatchTitle' for the backward compatibility.
+func newFieldMatchers(source config.RSSSourceConfig) ([]fieldMatcher, error) {
+	return compileFieldMatchers([]fieldMatchConfig{
+		{fieldDescription, source.MatchDescription},
+		{fieldCategory, source.MatchCategories},
+		{fieldAuthor, source.MatchAuthor},
+	})
+}
+
+// newExcludeMatchers compiles the exclude matchers, an item
+// is excluded if any of them match.
+func newExcludeMatchers(c config.RSSExcludeConfig) ([]fieldMatcher, error) {
+	return compileFieldMatchers([]fieldMatchConfig{
+		{fieldTitle, c.Title},
+		{fieldDescription, c.Description},
+		{fieldCategory, c.Categories},
+		{fieldAuthor, c.Author},
+	})
+}
+
+// compileFieldMatchers compiles the matchers, skipping the empty ones.
+func compileFieldMatchers(configs []fieldMatchConfig) ([]fieldMatcher, error) {
+	var result []fieldMatcher
+
+	for _, c := range configs {
+		m, err := newFieldMatcher(c.field, c.config)
+		if err != nil {
+			return nil, err
+		}
+
+		if !m.empty() {
+			result = append(result, m)
+		}
+	}
+
+	return result, nil
+}
+
+// hasMatchers reports whether the source has any matcher, except the expression.
+func (r *RSS) hasMatchers(k string) bool {
+	title := r.sourceConfigMap[k].MatchTitle
+
+	return len(title.Contains) > 0 || len(title.Regexes) > 0 || len(r.matchersMap[k]) > 0
+}
+
+// scoreRegExp matches the points in the description, i.e. "Points: 42" of hnrss.
+var scoreRegExp = regexp.MustCompile(`(?i)\bpoints:\s*(\d+)`)
+
+// getScore returns the points of the item if the feed provides them, else 0.
+func getScore(item *gofeed.Item) int {
+	m := scoreRegExp.FindStringSubmatch(stripHTML(item.Description))
+	if m == nil {
+		return 0
+	}
+
+	score, _ := strconv.Atoi(m[1])
+
+	return score
+}
@@ -3,19 +3,21 @@ package rss
 import (
 	"context"
 	"fmt"
+	"log"
 	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Dentrax/remind-us/pkg/config"
 	"github.com/Dentrax/remind-us/pkg/integrations"
+	"github.com/Dentrax/remind-us/pkg/reminder"
+	"github.com/Dentrax/remind-us/pkg/state"
 	"github.com/hako/durafmt"
 	"github.com/mmcdole/gofeed"
 	"github.com/pkg/errors"
-	"github.com/slack-go/slack"
-	"golang.org/x/sync/errgroup"
 )
 
 var errLoad = errors.New("rss is not loaded")
@@ -25,14 +27,59 @@ type RSS struct {
 
 	// result stores fetched sources
 	// from remote.
+	//
+	// map: K: RSS Source URL, V: Feed
 	result map[string]*gofeed.Feed
 
+	// rules stores the keys of the sources in the given order. The
+	// same URL can be declared as distinct rules by their names, where
+	// the key is the URL suffixed by the name, i.e. "<url>#cve".
+	// The maps below are keyed by them, unless noted.
+	rules []string
+
+	// urlMap stores the URL of each rule, each URL
+	// is fetched once for all the rules of it.
+	//
+	// map: K: Rule key, V: RSS Source URL
+	urlMap map[string]string
+
 	// sinceMap stores time.Duration
 	// for each given RSS URL.
 	//
 	// map: K: RSS Source URL, V: time.Duration
 	sinceMap map[string]time.Duration
 
+	// policyMap stores how to fetch each given RSS URL,
+	// by the first rule of it.
+	//
+	// map: K: RSS Source URL, V: fetchPolicy
+	policyMap map[string]fetchPolicy
+
+	// sectionOrder and itemOrder store how the
+	// reminder is sorted, by the order config.
+	sectionOrder reminder.SectionOrder
+	itemOrder    reminder.ItemOrder
+
+	// fetched stores the cache validators of the sources
+	// fetched on the last Load, they get persisted in
+	// State on Commit, not to miss the items if the
+	// alerting fails. Without State, they are not sent.
+	//
+	// map: K: RSS Source URL, V: validators
+	fetched map[string]validators
+
+	// truncated stores the sources having items left out
+	// by the last GenerateReminder call, their validators
+	// are not persisted, not to skip the items left out
+	// if the sources are not modified.
+	//
+	// map: K: RSS Source URL, V: whether it is truncated
+	truncated map[string]bool
+
+	// failures stores the sources that could not be
+	// fetched on the last Load, in the given order.
+	failures []fetchResult
+
 	// sourceConfigMap stores RSSSourceConfig config
 	// for each given RSS URL.
 	sourceConfigMap map[string]config.RSSSourceConfig
@@ -45,6 +92,23 @@ type RSS struct {
 	// map: K: RSS Source URL, V: Compiled 'MatchTitle' RegExp Array
 	matchTitleRegExpMap map[string][]*regexp.Regexp
 
+	// matchersMap stores the compiled matchers of the
+	// other fields, generated by Validate function.
+	//
+	// map: K: RSS Source URL, V: Matchers except the title
+	matchersMap map[string][]fieldMatcher
+
+	// excludeMap stores the compiled exclude matchers.
+	//
+	// map: K: RSS Source URL, V: Exclude matchers
+	excludeMap map[string][]fieldMatcher
+
+	// expressionMap stores the compiled expressions,
+	// only for the sources having one.
+	//
+	// map: K: RSS Source URL, V: Compiled 'Expression'
+	expressionMap map[string]expression
+
 	// initialTime stores the time.Now just before
 	// the module is loaded. It would be inconsistent
 	// to get the time.Now after requests all RSSs concurrently.
@@ -52,6 +116,18 @@ type RSS struct {
 	// Will set from main.
 	InitialTime time.Time
 
+	// State stores the keys of the items that already
+	// alerted for each RSS URL, to not alert them again.
+	// It is optional, will set from main.
+	State state.IStore
+
+	// pending stores the keys of the items generated
+	// by the last GenerateReminder call, they get
+	// marked as alerted in State on Commit.
+	//
+	// map: K: RSS Source URL, V: Item keys
+	pending map[string][]string
+
 	// config stores the config of RSS
 	config *config.RSSIntegrationConfig
 }
@@ -72,21 +148,48 @@ func (r *RSS) Enabled(config config.Integrations) bool {
 
 func (r *RSS) Validate(c config.Integrations) error {
 	sinceMap := make(map[string]time.Duration, len(c.RSS.Sources))
+	policyMap := make(map[string]fetchPolicy, len(c.RSS.Sources))
 	matchTitleRegExpMap := make(map[string][]*regexp.Regexp, len(c.RSS.Sources))
+	matchersMap := make(map[string][]fieldMatcher, len(c.RSS.Sources))
+	excludeMap := make(map[string][]fieldMatcher, len(c.RSS.Sources))
+	expressionMap := make(map[string]expression, len(c.RSS.Sources))
+	urlMap := make(map[string]string, len(c.RSS.Sources))
+	rules := make([]string, 0, len(c.RSS.Sources))
 
-	// err same url exist
 	for _, source := range c.RSS.Sources {
 		_, err := url.ParseRequestURI(source.URL)
 		if err != nil {
 			return errors.Wrapf(err, "incorrect URL pattern: '%s'", source.URL)
 		}
 
+		k := ruleKey(source)
+
+		if _, ok := urlMap[k]; ok {
+			if source.Name == "" {
+				return errors.Errorf("duplicate RSS source: '%s', name the rules of the same URL", source.URL)
+			}
+
+			return errors.Errorf("duplicate RSS rule name: '%s' of '%s'", source.Name, source.URL)
+		}
+
+		urlMap[k] = source.URL
+		rules = append(rules, k)
+
 		since, err := time.ParseDuration(source.Since)
 		if err != nil {
 			return errors.Wrapf(err, "incorrect 'since' pattern: '%s'", source.Since)
 		}
 
-		sinceMap[source.URL] = since
+		sinceMap[k] = since
+
+		if _, ok := policyMap[source.URL]; !ok {
+			policy, err := newFetchPolicy(c.RSS, source)
+			if err != nil {
+				return errors.Wrapf(err, "incorrect fetch policy of: '%s'", source.URL)
+			}
+
+			policyMap[source.URL] = policy
+		}
 
 		rgxs := make([]*regexp.Regexp, len(source.MatchTitle.Regexes))
 
@@ -99,54 +202,120 @@ func (r *RSS) Validate(c config.Integrations) error {
 			rgxs[i] = re
 		}
 
-		matchTitleRegExpMap[source.URL] = rgxs
+		matchTitleRegExpMap[k] = rgxs
+
+		matchers, err := newFieldMatchers(source)
+		if err != nil {
+			return err
+		}
+
+		matchersMap[k] = matchers
+
+		excludes, err := newExcludeMatchers(source.Exclude)
+		if err != nil {
+			return errors.Wrap(err, "incorrect 'exclude'")
+		}
+
+		excludeMap[k] = excludes
+
+		if source.Expression != "" {
+			e, err := compileExpression(source.Expression)
+			if err != nil {
+				return err
+			}
+
+			expressionMap[k] = e
+		}
+	}
+
+	sectionOrder, err := reminder.ParseSectionOrder(c.RSS.Order.Sections)
+	if err != nil {
+		return errors.Wrap(err, "incorrect 'order'")
 	}
 
+	itemOrder, err := reminder.ParseItemOrder(c.RSS.Order.Items)
+	if err != nil {
+		return errors.Wrap(err, "incorrect 'order'")
+	}
+
+	r.sectionOrder = sectionOrder
+	r.itemOrder = itemOrder
 	r.matchTitleRegExpMap = matchTitleRegExpMap
+	r.matchersMap = matchersMap
+	r.excludeMap = excludeMap
+	r.expressionMap = expressionMap
 	r.sinceMap = sinceMap
+	r.policyMap = policyMap
+	r.urlMap = urlMap
+	r.rules = rules
 	r.Validated = true
 
 	return nil
 }
 
+// Load fetches all the sources, the failing ones are logged and
+// skipped. It fails only if none of the sources could be fetched.
 func (r *RSS) Load(c config.Integrations) error {
-	fp := gofeed.NewParser()
-
 	sourceMap := make(map[string]config.RSSSourceConfig, len(c.RSS.Sources))
 	feeds := make(map[string]*gofeed.Feed, len(c.RSS.Sources))
+	urls := make([]string, 0, len(c.RSS.Sources))
 
 	for _, source := range c.RSS.Sources {
-		sourceMap[source.URL] = source
+		sourceMap[ruleKey(source)] = source
+
+		// fetch once for all the rules of the URL
+		if _, ok := r.policyMap[source.URL]; ok && !containsString(urls, source.URL) {
+			urls = append(urls, source.URL)
+		}
+	}
+
+	if r.State == nil && len(urls) > 0 {
+		statelessOnce.Do(func() {
+			log.Printf("no state is configured, RSS sources are downloaded on each run without conditional requests\n")
+		})
 	}
 
-	g, ctx := errgroup.WithContext(context.Background())
+	var failures []fetchResult
 
-	for _, s := range c.RSS.Sources {
-		u := s.URL
+	fetched := make(map[string]validators, len(urls))
 
-		g.Go(func() error {
-			feed, err := fp.ParseURLWithContext(u, ctx)
-			if err != nil {
-				return err
-			}
-			feeds[u] = feed
-			return nil
-		})
+	for _, result := range fetchAll(context.Background(), urls, r.policyMap, r.loadValidators(urls)) {
+		if result.err != nil {
+			log.Printf("unable to fetch RSS source: '%s': %v\n", result.url, result.err)
+
+			failures = append(failures, result)
+
+			continue
+		}
+
+		if !result.validators.empty() {
+			fetched[result.url] = result.validators
+		}
+
+		if result.notModified {
+			log.Printf("RSS source is not modified: '%s'\n", result.url)
+
+			continue
+		}
+
+		feeds[result.url] = result.feed
 	}
 
-	if err := g.Wait(); err != nil {
-		return errors.Wrapf(err, "Could not fetch all RSS sources")
+	if len(urls) > 0 && len(failures) == len(urls) {
+		return errors.Wrapf(failures[0].err, "Could not fetch any of the RSS sources")
 	}
 
 	r.sourceConfigMap = sourceMap
 	r.result = feeds
+	r.failures = failures
+	r.fetched = fetched
 	r.config = c.RSS
 	r.Loaded = true
 
 	return nil
 }
 
-func (r *RSS) GenerateSlackMessage(options integrations.GenerateMessageOptions) (*slack.WebhookMessage, error) {
+func (r *RSS) GenerateReminder(options integrations.GenerateMessageOptions) (*reminder.Reminder, error) {
 	if !r.Loaded {
 		return nil, errLoad
 	}
@@ -170,9 +339,16 @@ func (r *RSS) GenerateSlackMessage(options integrations.GenerateMessageOptions)
 		return t
 	}
 
-	attachments := make([]slack.Attachment, 0, len(r.result))
+	sections := make([]reminder.Section, 0, len(r.result))
+
+	r.pending = make(map[string][]string, len(r.result))
+
+	for _, k := range r.rules {
+		v, ok := r.result[r.urlMap[k]]
+		if !ok {
+			continue
+		}
 
-	for k, v := range r.result {
 		items := make([]*gofeed.Item, 0, len(v.Items))
 
 		for _, i := range v.Items {
@@ -201,6 +377,32 @@ func (r *RSS) GenerateSlackMessage(options integrations.GenerateMessageOptions)
 				}
 			}
 
+			// Then the other fields
+			if !flag {
+				for _, m := range r.matchersMap[k] {
+					if m.Match(i) {
+						flag = true
+						break
+					}
+				}
+			}
+
+			// The expression must hold, it selects the
+			// items alone if there is no other matcher
+			if e, ok := r.expressionMap[k]; ok {
+				flag = (flag || !r.hasMatchers(k)) && e.eval(i)
+			}
+
+			// Excludes take precedence over all
+			if flag {
+				for _, m := range r.excludeMap[k] {
+					if m.Match(i) {
+						flag = false
+						break
+					}
+				}
+			}
+
 			if flag {
 				since := r.InitialTime.Sub(*timeParsed(i.PublishedParsed, i.UpdatedParsed))
 
@@ -208,6 +410,12 @@ func (r *RSS) GenerateSlackMessage(options integrations.GenerateMessageOptions)
 					continue
 				}
 
+				if r.State != nil {
+					if _, ok := r.State.Get(k, itemKey(i)); ok {
+						continue
+					}
+				}
+
 				items = append(items, i)
 			}
 		}
@@ -216,7 +424,7 @@ func (r *RSS) GenerateSlackMessage(options integrations.GenerateMessageOptions)
 			continue
 		}
 
-		fields := make([]slack.AttachmentField, len(items))
+		ritems := make([]reminder.Item, len(items))
 
 		for i, item := range items {
 			// Some RSS feeds stores their official link in GUID field (i.e. HN)
@@ -236,32 +444,250 @@ func (r *RSS) GenerateSlackMessage(options integrations.GenerateMessageOptions)
 			}(item.Link, item.GUID)
 
 			// Some RSS feeds use updated field only, to prevent this, we should check both
-			getTimeString := func(published, updated *time.Time) string {
-				t := getTime(published, updated)
-
+			getTimeText := func(t *time.Time) reminder.Text {
 				if t != nil {
-					return fmt.Sprintf("(%s ago)", durafmt.Parse(r.InitialTime.Sub(*t)).LimitFirstN(1).String())
+					return reminder.Text{reminder.Plain(fmt.Sprintf("(%s ago)", durafmt.Parse(r.InitialTime.Sub(*t)).LimitFirstN(1).String()))}
 				}
 
-				return ""
+				return nil
 			}
 
-			fields[i] = slack.AttachmentField{
-				Title: "",
-				Value: fmt.Sprintf("• <%s|%s> %s", getLink, item.Title, getTimeString(item.PublishedParsed, item.UpdatedParsed)),
-				Short: false,
+			t := getTime(item.PublishedParsed, item.UpdatedParsed)
+
+			ritems[i] = reminder.Item{
+				Title:   item.Title,
+				Link:    getLink,
+				Details: getTimeText(t),
+				Time:    t,
+				Score:   getScore(item),
+				Key:     pendingKey(k, itemKey(item)),
 			}
 		}
 
-		attachments = append(attachments, slack.Attachment{
-			Color:      "good",
-			AuthorName: v.Title,
-			AuthorLink: v.Link,
-			Fields:     fields,
+		title := v.Title
+		if name := r.sourceConfigMap[k].Name; name != "" {
+			title = fmt.Sprintf("%s (%s)", title, name)
+		}
+
+		sections = append(sections, reminder.Section{
+			Title:    title,
+			Link:     v.Link,
+			Items:    ritems,
+			Severity: reminder.SeverityOK,
+			Channel:  r.sourceConfigMap[k].Channel,
+			Limit:    r.sourceConfigMap[k].MaxItems,
 		})
 	}
 
-	return &slack.WebhookMessage{
-		Attachments: attachments,
-	}, nil
+	rem := &reminder.Reminder{
+		Source:   r.Name(),
+		Sections: sections,
+	}
+
+	rem.Sort(r.sectionOrder, r.itemOrder)
+
+	keys := sectionKeys(rem)
+
+	// the items left out are summarized, they are not remembered
+	// as alerted, so they are alerted by the next runs
+	if r.config != nil {
+		rem.Truncate(r.config.MaxItems)
+	}
+
+	r.truncated = r.truncatedSources(keys, rem)
+
+	if r.State != nil {
+		r.addPending(rem)
+	}
+
+	// the failures are always the last
+	if r.config != nil && r.config.ReportFailures && len(r.failures) > 0 {
+		rem.Sections = append(rem.Sections, r.generateFailureSection())
+	}
+
+	return rem, nil
+}
+
+// addPending adds the items of the reminder to the pending ones, to
+// mark them as alerted on Commit. The keys are parsed by pendingKey.
+func (r *RSS) addPending(rem *reminder.Reminder) {
+	for _, s := range rem.Sections {
+		for _, item := range s.Items {
+			if k, key, ok := parsePendingKey(item.Key); ok {
+				r.pending[k] = append(r.pending[k], key)
+			}
+		}
+	}
+}
+
+// sectionKeys returns the source keys of the sections, by their
+// first items, since the sections may be emptied by Truncate.
+func sectionKeys(rem *reminder.Reminder) []string {
+	keys := make([]string, len(rem.Sections))
+
+	for i, s := range rem.Sections {
+		if len(s.Items) > 0 {
+			keys[i], _, _ = parsePendingKey(s.Items[0].Key)
+		}
+	}
+
+	return keys
+}
+
+// truncatedSources returns the URLs of the sections having items
+// left out, by the source keys of the sections before Truncate.
+func (r *RSS) truncatedSources(keys []string, rem *reminder.Reminder) map[string]bool {
+	result := make(map[string]bool)
+
+	for i, s := range rem.Sections {
+		if s.More > 0 && keys[i] != "" {
+			result[r.urlMap[keys[i]]] = true
+		}
+	}
+
+	return result
+}
+
+// pendingKey returns the key of the item of the source, they
+// are separated by NUL, which can not be in a source key.
+func pendingKey(source, key string) string {
+	return source + "\x00" + key
+}
+
+func parsePendingKey(s string) (string, string, bool) {
+	i := strings.IndexByte(s, 0)
+	if i < 0 {
+		return "", "", false
+	}
+
+	return s[:i], s[i+1:], true
+}
+
+// generateFailureSection lists the sources that could not be fetched.
+func (r *RSS) generateFailureSection() reminder.Section {
+	items := make([]reminder.Item, len(r.failures))
+
+	for i, f := range r.failures {
+		items[i] = reminder.Item{
+			Status:  reminder.StatusFailed,
+			Title:   f.url,
+			Link:    f.url,
+			Details: reminder.Text{reminder.Plain(fmt.Sprintf("(%v)", f.err))},
+		}
+	}
+
+	noun := "source"
+	if len(r.failures) > 1 {
+		noun = "sources"
+	}
+
+	return reminder.Section{
+		Title:    "RSS",
+		Summary:  reminder.Text{reminder.Plain(fmt.Sprintf("Could not fetch %d RSS %s.", len(r.failures), noun))},
+		Items:    items,
+		Severity: reminder.SeverityWarning,
+	}
+}
+
+// Commit marks the items generated by the last
+// GenerateReminder call as alerted in the State.
+func (r *RSS) Commit() error {
+	if r.State == nil {
+		return nil
+	}
+
+	now := time.Now()
+
+	for k, keys := range r.pending {
+		for _, key := range keys {
+			r.State.Put(k, key, state.Entry{
+				Time: now,
+			})
+		}
+	}
+
+	for u, v := range r.fetched {
+		// the next run must download the sources having items left out
+		if r.truncated[u] {
+			v = validators{}
+		}
+
+		r.State.Put(validatorsNamespace(u), etagKey, state.Entry{Value: v.etag, Time: now})
+		r.State.Put(validatorsNamespace(u), lastModifiedKey, state.Entry{Value: v.lastModified, Time: now})
+	}
+
+	if err := r.State.Save(); err != nil {
+		return errors.Wrap(err, "unable to save RSS state")
+	}
+
+	r.pending = nil
+
+	return nil
+}
+
+// ruleKey returns the key of the source, the URL
+// suffixed by the name of the rule, if any.
+func ruleKey(source config.RSSSourceConfig) string {
+	if source.Name == "" {
+		return source.URL
+	}
+
+	return source.URL + "#" + source.Name
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// statelessOnce logs once that the cache validators
+// are not sent, since they are persisted only in State.
+var statelessOnce sync.Once
+
+const (
+	etagKey         = "etag"
+	lastModifiedKey = "last-modified"
+)
+
+// validatorsNamespace is the State namespace of
+// the cache validators of the RSS URL.
+func validatorsNamespace(u string) string {
+	return "http:" + u
+}
+
+// loadValidators loads the cache validators of the given
+// sources from the State, if any.
+func (r *RSS) loadValidators(urls []string) map[string]validators {
+	result := make(map[string]validators, len(urls))
+
+	if r.State == nil {
+		return result
+	}
+
+	for _, u := range urls {
+		etag, _ := r.State.Get(validatorsNamespace(u), etagKey)
+		lastModified, _ := r.State.Get(validatorsNamespace(u), lastModifiedKey)
+
+		result[u] = validators{
+			etag:         etag.Value,
+			lastModified: lastModified.Value,
+		}
+	}
+
+	return result
+}
+
+// itemKey returns the unique key of the item, some
+// RSS feeds do not provide GUID so we fallback to link.
+func itemKey(i *gofeed.Item) string {
+	if i.GUID != "" {
+		return i.GUID
+	}
+
+	return i.Link
 }
@@ -2,14 +2,22 @@ package rss
 
 import (
 	"bufio"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"regexp"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"bou.ke/monkey"
+	slackalerter "github.com/Dentrax/remind-us/pkg/alerters/slack"
 	"github.com/Dentrax/remind-us/pkg/config"
 	"github.com/Dentrax/remind-us/pkg/integrations"
+	"github.com/Dentrax/remind-us/pkg/reminder"
+	"github.com/Dentrax/remind-us/pkg/state"
 	"github.com/mmcdole/gofeed"
 	"github.com/pkg/errors"
 	"github.com/slack-go/slack"
@@ -57,16 +65,18 @@ func load(path, url string, matchConf config.RSSMatchConfig) (*RSS, error) {
 	sourceConfigMap[url] = cfg.Sources[0]
 
 	return &RSS{
-		integrations.Integration{
+		Integration: integrations.Integration{
 			Validated: true,
 			Loaded:    true,
 		},
-		result,
-		sinceMap,
-		sourceConfigMap,
-		matchTitleRegExpMap,
-		time.Now(),
-		cfg,
+		result:              result,
+		rules:               []string{url},
+		urlMap:              map[string]string{url: url},
+		sinceMap:            sinceMap,
+		sourceConfigMap:     sourceConfigMap,
+		matchTitleRegExpMap: matchTitleRegExpMap,
+		InitialTime:         time.Now(),
+		config:              cfg,
 	}, nil
 }
 
@@ -163,13 +173,15 @@ func TestRSS_GenerateMessage_Slack(t *testing.T) {
 			assert.NoError(t, err)
 			assert.NotNil(t, r)
 
-			got, err := r.GenerateSlackMessage(tt.options)
+			rem, err := r.GenerateReminder(tt.options)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("GenerateSlackMessage() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("GenerateReminder() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 
-			assert.NotNil(t, got)
+			assert.NotNil(t, rem)
+
+			got := slackalerter.Render(rem)
 
 			assert.Len(t, got.Attachments, len(tt.want.Attachments))
 
@@ -179,3 +191,567 @@ func TestRSS_GenerateMessage_Slack(t *testing.T) {
 		})
 	}
 }
+
+func TestRSS_GenerateMessage_State(t *testing.T) {
+	t.Parallel()
+
+	r, err := load("../../../testdata/integrations/rss/hn_frontpage.rss", "https://hnrss.org/frontpage", config.RSSMatchConfig{
+		Contains: []string{"games"},
+	})
+	assert.NoError(t, err)
+
+	r.InitialTime = time.Date(2021, time.March, 24, 20, 0o5, 7, 7, time.UTC)
+
+	r.State = state.NewMemoryStore(time.Hour)
+	r.State.Put("https://hnrss.org/frontpage", "https://news.ycombinator.com/item?id=26369653", state.Entry{Time: time.Now()})
+
+	got, err := r.GenerateReminder(integrations.GenerateMessageOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, got.Sections, 1)
+	assert.Len(t, got.Sections[0].Items, 1)
+	assert.Equal(t, "Dos.Zone – interactive database of DOS games", got.Sections[0].Items[0].Title)
+
+	assert.NoError(t, r.Commit())
+
+	got, err = r.GenerateReminder(integrations.GenerateMessageOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, got.Sections, 0)
+}
+
+func TestRSS_Load_Failures(t *testing.T) {
+	t.Parallel()
+
+	feed, err := ioutil.ReadFile("../../../testdata/integrations/rss/hn_frontpage.rss")
+	assert.NoError(t, err)
+
+	var flakyCalls int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/flaky":
+			// fails on the first attempt only
+			if atomic.AddInt32(&flakyCalls, 1) == 1 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+		case "/down":
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		_, _ = w.Write(feed)
+	}))
+
+	t.Cleanup(ts.Close)
+
+	c := config.Integrations{
+		RSS: &config.RSSIntegrationConfig{
+			Enabled:        "true",
+			ReportFailures: true,
+			Sources: []config.RSSSourceConfig{
+				{URL: ts.URL + "/ok", Since: "1h"},
+				{URL: ts.URL + "/flaky", Since: "1h", Retries: intPtr(1)},
+				{URL: ts.URL + "/down", Since: "1h", Timeout: "5s"},
+			},
+		},
+	}
+
+	r := &RSS{InitialTime: time.Date(2021, time.March, 24, 20, 0o5, 7, 7, time.UTC)}
+
+	assert.NoError(t, r.Validate(c))
+
+	// do not wait between the attempts
+	for u, p := range r.policyMap {
+		p.wait = 0
+		r.policyMap[u] = p
+	}
+
+	assert.NoError(t, r.Load(c))
+	assert.Len(t, r.result, 2)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&flakyCalls))
+
+	got, err := r.GenerateReminder(integrations.GenerateMessageOptions{})
+	assert.NoError(t, err)
+
+	failure := got.Sections[len(got.Sections)-1]
+
+	assert.Equal(t, reminder.SeverityWarning, failure.Severity)
+	assert.Equal(t, "Could not fetch 1 RSS source.", failure.Summary.String())
+	assert.Equal(t, ts.URL+"/down", failure.Items[0].Title)
+
+	// it should fail if none of them could be fetched
+	c.RSS.Sources = c.RSS.Sources[2:]
+
+	assert.NoError(t, r.Validate(c))
+	assert.Error(t, r.Load(c))
+}
+
+func TestNewFetchPolicy(t *testing.T) {
+	t.Parallel()
+
+	c := &config.RSSIntegrationConfig{Timeout: "10s", Retries: 2}
+
+	p, err := newFetchPolicy(c, config.RSSSourceConfig{})
+	assert.NoError(t, err)
+	assert.Equal(t, fetchPolicy{timeout: 10 * time.Second, retries: 2, wait: defaultRetryWait, userAgent: defaultUserAgent}, p)
+
+	p, err = newFetchPolicy(&config.RSSIntegrationConfig{UserAgent: "foo/1.0"}, config.RSSSourceConfig{Timeout: "1m", Retries: intPtr(5), Headers: map[string]string{"authorization": "Bearer bar"}})
+	assert.NoError(t, err)
+	assert.Equal(t, fetchPolicy{timeout: time.Minute, retries: 5, wait: defaultRetryWait, userAgent: "foo/1.0", headers: map[string]string{"authorization": "Bearer bar"}}, p)
+
+	p, err = newFetchPolicy(&config.RSSIntegrationConfig{}, config.RSSSourceConfig{})
+	assert.NoError(t, err)
+	assert.Equal(t, defaultTimeout, p.timeout)
+
+	_, err = newFetchPolicy(c, config.RSSSourceConfig{Timeout: "foo"})
+	assert.Error(t, err)
+
+	// it should turn off the retries of the integration
+	p, err = newFetchPolicy(c, config.RSSSourceConfig{Retries: intPtr(0)})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, p.retries)
+
+	_, err = newFetchPolicy(c, config.RSSSourceConfig{Retries: intPtr(-1)})
+	assert.Error(t, err)
+}
+
+func TestFetchOnce_UserAgent(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "bar/2.0", r.Header.Get("User-Agent"))
+
+		w.WriteHeader(http.StatusNotModified)
+	}))
+
+	t.Cleanup(ts.Close)
+
+	p := fetchPolicy{timeout: time.Second, userAgent: "foo/1.0", headers: map[string]string{"user-agent": "bar/2.0"}}
+
+	result := fetchOnce(context.Background(), ts.URL, p, validators{})
+	assert.NoError(t, result.err)
+	assert.True(t, result.notModified, "it should send the User-Agent of the source")
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestFieldMatcher_Match(t *testing.T) {
+	t.Parallel()
+
+	item := &gofeed.Item{
+		Title:       "Kubernetes v1.21 released",
+		Description: "<p>Fixes <b>CVE-2021-25735</b> &amp; more</p>",
+		Content:     "<div>Thanks to all the contributors</div>",
+		Categories:  []string{"Security", "kubernetes"},
+		Author:      &gofeed.Person{Name: "Foo Bar", Email: "foo@bar.baz"},
+	}
+
+	tests := []struct {
+		name   string
+		field  field
+		config config.RSSMatchConfig
+		want   bool
+	}{
+		{"it should match the description", fieldDescription, config.RSSMatchConfig{Contains: []string{"cve"}}, true},
+		{"it should match the content", fieldDescription, config.RSSMatchConfig{Contains: []string{"contributors"}}, true},
+		{"it should strip the HTML", fieldDescription, config.RSSMatchConfig{Regexes: []string{`CVE-\d+-\d+ & more$`}}, true},
+		{"it should not match the HTML tags", fieldDescription, config.RSSMatchConfig{Contains: []string{"<b>"}}, false},
+		{"it should match the categories", fieldCategory, config.RSSMatchConfig{Regexes: []string{`^security$`, `^Security$`}}, true},
+		{"it should not match the other categories", fieldCategory, config.RSSMatchConfig{Contains: []string{"golang"}}, false},
+		{"it should match the author name", fieldAuthor, config.RSSMatchConfig{Contains: []string{"foo bar"}}, true},
+		{"it should match the author email", fieldAuthor, config.RSSMatchConfig{Regexes: []string{`@bar\.baz$`}}, true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			m, err := newFieldMatcher(tt.field, tt.config)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, m.Match(item))
+		})
+	}
+
+	_, err := newFieldMatchers(config.RSSSourceConfig{MatchAuthor: config.RSSMatchConfig{Regexes: []string{"("}}})
+	assert.Error(t, err)
+
+	assert.False(t, fieldMatcher{field: fieldAuthor, contains: []string{"foo"}}.Match(&gofeed.Item{}))
+}
+
+func TestCompileExpression(t *testing.T) {
+	t.Parallel()
+
+	item := &gofeed.Item{
+		Title:      "Fix for CVE-2021-25735",
+		Categories: []string{"Security", "kubernetes"},
+		Author:     &gofeed.Person{Name: "Foo"},
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		want       bool
+		wantErr    string
+	}{
+		{"it should match by RegExp", `title ~ "CVE-\d+"`, true, ""},
+		{"it should match by equality case-insensitively", `category == "SECURITY"`, true, ""},
+		{"it should match by contains", `author contains 'fo'`, true, ""},
+		{"it should negate the operator", `category != "golang" and title !~ "(?i)rumor"`, true, ""},
+		{"it should combine", `title ~ "CVE" and not title ~ "(?i)rumor" and category == "kubernetes"`, true, ""},
+		{"it should respect the precedence", `title ~ "foo" and category == "golang" or author == "foo"`, true, ""},
+		{"it should respect the parentheses", `title ~ "foo" and (category == "golang" or author == "foo")`, false, ""},
+		{"it should escape the quotes", `title !~ "\"quoted\""`, true, ""},
+		{"it should fail on unknown fields", `body ~ "CVE"`, false, "unknown field 'body' at position 1"},
+		{"it should fail on missing operators", `title "CVE"`, false, "expected an operator, got \"CVE\" at position 7"},
+		{"it should fail on bad RegExps", `title ~ "("`, false, "incorrect RegExp pattern at position 9"},
+		{"it should fail on unterminated strings", `title ~ "CVE`, false, "unterminated string at position 9"},
+		{"it should fail on unclosed parentheses", `(title ~ "CVE"`, false, "expected ')', got end of expression"},
+		{"it should fail on trailing tokens", `title ~ "CVE" )`, false, "unexpected ')' at position 15"},
+		{"it should fail on unknown operators", `title = "CVE"`, false, "unexpected '=' at position 7"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			e, err := compileExpression(tt.expression)
+			if tt.wantErr != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tt.wantErr)
+					assert.Contains(t, err.Error(), tt.expression)
+				}
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, e.eval(item))
+		})
+	}
+}
+
+func TestRSS_Validate_Expression(t *testing.T) {
+	t.Parallel()
+
+	r := &RSS{}
+
+	err := r.Validate(config.Integrations{
+		RSS: &config.RSSIntegrationConfig{
+			Sources: []config.RSSSourceConfig{
+				{URL: "https://hnrss.org/frontpage", Since: "1h", Expression: `title ~ "CVE" and`},
+			},
+		},
+	})
+	assert.EqualError(t, err, `incorrect expression: 'title ~ "CVE" and': expected a field, got end of expression`)
+}
+
+func TestRSS_GenerateMessage_Exclude(t *testing.T) {
+	t.Parallel()
+
+	r, err := load("../../../testdata/integrations/rss/hn_frontpage.rss", "https://hnrss.org/frontpage", config.RSSMatchConfig{
+		Contains: []string{"games"},
+	})
+	assert.NoError(t, err)
+
+	r.InitialTime = time.Date(2021, time.March, 24, 20, 0o5, 7, 7, time.UTC)
+
+	got, err := r.GenerateReminder(integrations.GenerateMessageOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, got.Sections[0].Items, 2)
+
+	excludes, err := newExcludeMatchers(config.RSSExcludeConfig{Title: config.RSSMatchConfig{Contains: []string{"dos"}}})
+	assert.NoError(t, err)
+
+	r.excludeMap = map[string][]fieldMatcher{"https://hnrss.org/frontpage": excludes}
+
+	got, err = r.GenerateReminder(integrations.GenerateMessageOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, got.Sections[0].Items, 1)
+	assert.NotContains(t, got.Sections[0].Items[0].Title, "DOS")
+
+	// the expression alone selects the items
+	e, err := compileExpression(`title contains "dos"`)
+	assert.NoError(t, err)
+
+	r.sourceConfigMap["https://hnrss.org/frontpage"] = config.RSSSourceConfig{}
+	r.excludeMap = nil
+	r.expressionMap = map[string]expression{"https://hnrss.org/frontpage": e}
+
+	got, err = r.GenerateReminder(integrations.GenerateMessageOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, got.Sections[0].Items, 1)
+}
+
+func TestRSS_Load_Conditional(t *testing.T) {
+	t.Parallel()
+
+	feed, err := ioutil.ReadFile("../../../testdata/integrations/rss/hn_frontpage.rss")
+	assert.NoError(t, err)
+
+	var parsed int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "foo/1.0", r.Header.Get("User-Agent"))
+		assert.Equal(t, "Bearer bar", r.Header.Get("Authorization"))
+
+		if r.Header.Get("If-None-Match") == `"v1"` && r.Header.Get("If-Modified-Since") == "Wed, 24 Mar 2021 20:00:00 GMT" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		atomic.AddInt32(&parsed, 1)
+
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Wed, 24 Mar 2021 20:00:00 GMT")
+		_, _ = w.Write(feed)
+	}))
+
+	t.Cleanup(ts.Close)
+
+	c := config.Integrations{
+		RSS: &config.RSSIntegrationConfig{
+			Enabled:   "true",
+			UserAgent: "foo/1.0",
+			Sources: []config.RSSSourceConfig{
+				{
+					URL:        ts.URL,
+					Since:      "1h",
+					Headers:    map[string]string{"authorization": "Bearer bar"},
+					MatchTitle: config.RSSMatchConfig{Contains: []string{"games"}},
+				},
+			},
+		},
+	}
+
+	store := state.NewMemoryStore(time.Hour)
+
+	for run := 0; run < 3; run++ {
+		r := &RSS{
+			InitialTime: time.Date(2021, time.March, 24, 20, 0o5, 7, 7, time.UTC),
+			State:       store,
+		}
+
+		assert.NoError(t, r.Validate(c))
+		assert.NoError(t, r.Load(c))
+
+		got, err := r.GenerateReminder(integrations.GenerateMessageOptions{})
+		assert.NoError(t, err)
+
+		switch run {
+		case 0:
+			// the validators are not persisted until the commit
+			assert.Len(t, got.Sections, 1)
+		case 1:
+			assert.Len(t, got.Sections, 1)
+			assert.NoError(t, r.Commit())
+		case 2:
+			// not modified
+			assert.Len(t, got.Sections, 0)
+		}
+	}
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&parsed))
+}
+
+func TestRSS_Load_Conditional_Truncated(t *testing.T) {
+	t.Parallel()
+
+	feed, err := ioutil.ReadFile("../../../testdata/integrations/rss/hn_frontpage.rss")
+	assert.NoError(t, err)
+
+	var notModified int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write(feed)
+	}))
+
+	t.Cleanup(ts.Close)
+
+	c := config.Integrations{
+		RSS: &config.RSSIntegrationConfig{
+			Enabled:  "true",
+			MaxItems: 1,
+			Sources: []config.RSSSourceConfig{
+				{URL: ts.URL, Since: "18h", MatchTitle: config.RSSMatchConfig{Contains: []string{"games"}}},
+			},
+		},
+	}
+
+	store := state.NewMemoryStore(time.Hour)
+
+	var titles []string
+
+	for run := 0; run < 3; run++ {
+		r := &RSS{
+			InitialTime: time.Date(2021, time.March, 24, 20, 0o5, 7, 7, time.UTC),
+			State:       store,
+		}
+
+		assert.NoError(t, r.Validate(c))
+		assert.NoError(t, r.Load(c))
+
+		got, err := r.GenerateReminder(integrations.GenerateMessageOptions{})
+		assert.NoError(t, err)
+
+		for _, s := range got.Sections {
+			for _, item := range s.Items {
+				titles = append(titles, item.Title)
+			}
+		}
+
+		assert.NoError(t, r.Commit())
+	}
+
+	// the item left out by the first run is alerted by the second
+	// one, then the validators are persisted and it is not modified
+	assert.Len(t, titles, 2)
+	assert.NotEqual(t, titles[0], titles[1])
+	assert.Equal(t, int32(1), atomic.LoadInt32(&notModified))
+}
+
+func TestRSS_Rules(t *testing.T) {
+	t.Parallel()
+
+	feed, err := ioutil.ReadFile("../../../testdata/integrations/rss/hn_frontpage.rss")
+	assert.NoError(t, err)
+
+	var calls int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+
+		_, _ = w.Write(feed)
+	}))
+
+	t.Cleanup(ts.Close)
+
+	c := config.Integrations{
+		RSS: &config.RSSIntegrationConfig{
+			Enabled: "true",
+			Sources: []config.RSSSourceConfig{
+				{URL: ts.URL, Name: "games", Channel: "#games", Since: "18h", MatchTitle: config.RSSMatchConfig{Contains: []string{"games"}}},
+				{URL: ts.URL, Name: "dos", Since: "18h", MatchTitle: config.RSSMatchConfig{Contains: []string{"dos"}}},
+			},
+		},
+	}
+
+	r := &RSS{InitialTime: time.Date(2021, time.March, 24, 20, 0o5, 7, 7, time.UTC)}
+
+	assert.NoError(t, r.Validate(c))
+	assert.NoError(t, r.Load(c))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	got, err := r.GenerateReminder(integrations.GenerateMessageOptions{})
+	assert.NoError(t, err)
+
+	if assert.Len(t, got.Sections, 2) {
+		assert.Equal(t, "Hacker News: Front Page (games)", got.Sections[0].Title)
+		assert.Equal(t, "#games", got.Sections[0].Channel)
+		assert.Len(t, got.Sections[0].Items, 2)
+
+		assert.Equal(t, "Hacker News: Front Page (dos)", got.Sections[1].Title)
+		assert.Equal(t, "", got.Sections[1].Channel)
+		assert.Len(t, got.Sections[1].Items, 1)
+	}
+
+	c.RSS.Sources[1].Name = ""
+	c.RSS.Sources = append(c.RSS.Sources, c.RSS.Sources[1])
+
+	assert.EqualError(t, r.Validate(c), "duplicate RSS source: '"+ts.URL+"', name the rules of the same URL")
+
+	c.RSS.Sources[1].Name = "games"
+
+	assert.EqualError(t, r.Validate(c), "duplicate RSS rule name: 'games' of '"+ts.URL+"'")
+}
+
+func TestRSS_GenerateMessage_MaxItems_State(t *testing.T) {
+	t.Parallel()
+
+	r, err := load("../../../testdata/integrations/rss/hn_frontpage.rss", "https://hnrss.org/frontpage", config.RSSMatchConfig{})
+	assert.NoError(t, err)
+
+	r.InitialTime = time.Date(2021, time.March, 24, 22, 0, 0, 0, time.UTC)
+	r.matchTitleRegExpMap["https://hnrss.org/frontpage"] = []*regexp.Regexp{regexp.MustCompile(".")}
+	r.config.MaxItems = 1
+	r.State = state.NewMemoryStore(time.Hour)
+
+	got, err := r.GenerateReminder(integrations.GenerateMessageOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, got.Sections[0].Items, 1)
+	assert.Greater(t, got.Sections[0].More, 0)
+
+	first := got.Sections[0].Items[0].Title
+
+	assert.NoError(t, r.Commit())
+
+	// the items left out are alerted by the next run
+	got, err = r.GenerateReminder(integrations.GenerateMessageOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, got.Sections[0].Items, 1)
+	assert.NotEqual(t, first, got.Sections[0].Items[0].Title)
+}
+
+func TestRSS_GenerateMessage_Order(t *testing.T) {
+	t.Parallel()
+
+	r, err := load("../../../testdata/integrations/rss/hn_frontpage.rss", "https://hnrss.org/frontpage", config.RSSMatchConfig{})
+	assert.NoError(t, err)
+
+	r.InitialTime = time.Date(2021, time.March, 24, 22, 0, 0, 0, time.UTC)
+	r.matchTitleRegExpMap["https://hnrss.org/frontpage"] = []*regexp.Regexp{regexp.MustCompile(".")}
+	r.itemOrder = reminder.ItemOrderScore
+
+	got, err := r.GenerateReminder(integrations.GenerateMessageOptions{})
+	assert.NoError(t, err)
+
+	items := got.Sections[0].Items
+	assert.Greater(t, len(items), 1)
+	assert.Greater(t, items[0].Score, 0)
+
+	for i := 1; i < len(items); i++ {
+		assert.GreaterOrEqual(t, items[i-1].Score, items[i].Score)
+	}
+
+	r.itemOrder = reminder.ItemOrderOldest
+
+	got, err = r.GenerateReminder(integrations.GenerateMessageOptions{})
+	assert.NoError(t, err)
+
+	items = got.Sections[0].Items
+
+	for i := 1; i < len(items); i++ {
+		assert.False(t, items[i].Time.Before(*items[i-1].Time))
+	}
+}
+
+func TestGetScore(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		description string
+		want        int
+	}{
+		{"hnrss", `<p>Article URL: <a href="https://example.com">https://example.com</a></p><p>Points: 42</p>`, 42},
+		{"no points", "<p>foo</p>", 0},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, getScore(&gofeed.Item{Description: tt.description}))
+		})
+	}
+}
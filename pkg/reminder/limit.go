@@ -0,0 +1,122 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reminder
+
+import "fmt"
+
+// Truncate leaves out the items exceeding the limit of their section,
+// then the ones exceeding max per message, 0 means no limit. Since the
+// sections are sent per channel and recipient, each of them has its own
+// max. The items are kept in order, so it should be called after Sort.
+func (r *Reminder) Truncate(max int) {
+	// map: K: message key, V: number of the items left
+	left := make(map[string]int)
+
+	for i := range r.Sections {
+		s := &r.Sections[i]
+
+		if s.Limit > 0 {
+			s.truncate(s.Limit)
+		}
+
+		if max > 0 {
+			k := s.messageKey()
+
+			if _, ok := left[k]; !ok {
+				left[k] = max
+			}
+
+			left[k] -= s.truncate(left[k])
+		}
+	}
+}
+
+// messageKey returns the key of the message the section is sent
+// in, the channel suffixed by the username of the recipient, if any.
+func (s Section) messageKey() string {
+	if s.Recipient == nil {
+		return s.Channel
+	}
+
+	return s.Channel + "\x00" + s.Recipient.Username
+}
+
+// Overflow returns the summary of the items left out, i.e.
+// "…and 14 more", nil if there is none.
+func (s Section) Overflow() Text {
+	if s.More <= 0 {
+		return nil
+	}
+
+	text := fmt.Sprintf("…and %d more", s.More)
+
+	link := s.MoreLink
+	if link == "" {
+		link = s.Link
+	}
+
+	if link == "" {
+		return Text{Plain(text)}
+	}
+
+	return Text{Link(text, link)}
+}
+
+// truncate keeps the first n items, the items come before the ones
+// of the groups, the groups left empty are removed and the others
+// are retitled. It returns the number of the items kept.
+func (s *Section) truncate(n int) int {
+	left := n
+
+	s.Items = s.truncateItems(s.Items, &left)
+
+	groups := s.Groups[:0]
+
+	for _, g := range s.Groups {
+		n := len(g.Items)
+
+		g.Items = s.truncateItems(g.Items, &left)
+
+		if len(g.Items) == 0 {
+			continue
+		}
+
+		if len(g.Items) < n && g.Retitle != nil {
+			g.Title = g.Retitle(len(g.Items))
+		}
+
+		groups = append(groups, g)
+	}
+
+	s.Groups = groups
+
+	return n - left
+}
+
+func (s *Section) truncateItems(items []Item, left *int) []Item {
+	if len(items) <= *left {
+		*left -= len(items)
+
+		return items
+	}
+
+	s.More += len(items) - *left
+	items = items[:*left]
+	*left = 0
+
+	return items
+}
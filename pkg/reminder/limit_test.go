@@ -0,0 +1,132 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reminder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReminder_Truncate(t *testing.T) {
+	t.Parallel()
+
+	newReminder := func(limit int) *Reminder {
+		return &Reminder{
+			Sections: []Section{
+				{Title: "a", Link: "https://a", Limit: limit, Items: []Item{{Title: "1"}, {Title: "2"}, {Title: "3"}}},
+				{Title: "b", MoreLink: "https://b/more", Groups: []Group{{Title: "x", Items: []Item{{Title: "4"}}}, {Title: "y", Items: []Item{{Title: "5"}}}}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		limit     int
+		max       int
+		wantItems []int
+		wantMore  []int
+	}{
+		{"no limit", 0, 0, []int{3, 2}, []int{0, 0}},
+		{"per section", 2, 0, []int{2, 2}, []int{1, 0}},
+		{"total", 0, 4, []int{3, 1}, []int{0, 1}},
+		{"both", 1, 2, []int{1, 1}, []int{2, 1}},
+		{"total exhausted", 0, 3, []int{3, 0}, []int{0, 2}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := newReminder(tt.limit)
+			r.Truncate(tt.max)
+
+			assert.Len(t, r.Sections, len(tt.wantItems))
+
+			for i, s := range r.Sections {
+				assert.Equal(t, tt.wantItems[i], s.countItems(), s.Title)
+				assert.Equal(t, tt.wantMore[i], s.More, s.Title)
+
+				for _, g := range s.Groups {
+					assert.NotEmpty(t, g.Items, s.Title)
+				}
+			}
+		})
+	}
+}
+
+func TestReminder_Truncate_Groups(t *testing.T) {
+	t.Parallel()
+
+	title := func(n int) string { return fmt.Sprintf("%d awaiting", n) }
+
+	r := &Reminder{
+		Sections: []Section{
+			{Title: "a", Groups: []Group{NewGroup([]Item{{Title: "1"}, {Title: "2"}, {Title: "3"}}, title)}},
+			{Title: "b", Channel: "#b", Items: []Item{{Title: "4"}}},
+			{Title: "c", MoreLink: "https://c", Items: []Item{{Title: "5"}, {Title: "6"}}},
+		},
+	}
+
+	r.Truncate(2)
+
+	assert.Len(t, r.Sections, 3)
+
+	assert.Equal(t, "2 awaiting", r.Sections[0].Groups[0].Title, "it should retitle the truncated groups")
+	assert.Equal(t, 1, r.Sections[0].More)
+
+	assert.Len(t, r.Sections[1].Items, 1, "it should not share the budget across the channels")
+	assert.Equal(t, 0, r.Sections[1].More)
+
+	assert.Empty(t, r.Sections[2].Items)
+	assert.Equal(t, Text{Link("…and 2 more", "https://c")}, r.Sections[2].Overflow(), "it should summarize the emptied sections by their own links")
+}
+
+func TestReminder_Truncate_Recipients(t *testing.T) {
+	t.Parallel()
+
+	r := &Reminder{
+		Sections: []Section{
+			{Title: "project", Items: []Item{{Title: "1"}, {Title: "2"}}},
+			{Title: "foo", Recipient: &User{Username: "foo"}, Items: []Item{{Title: "3"}, {Title: "4"}}},
+			{Title: "bar", Recipient: &User{Username: "bar"}, Items: []Item{{Title: "5"}}},
+			{Title: "foo again", Recipient: &User{Username: "foo"}, Items: []Item{{Title: "6"}}},
+		},
+	}
+
+	r.Truncate(2)
+
+	got := make([]int, 0, len(r.Sections))
+
+	for _, s := range r.Sections {
+		got = append(got, len(s.Items))
+	}
+
+	assert.Equal(t, []int{2, 2, 1, 0}, got, "it should have a budget for each recipient")
+	assert.Equal(t, 1, r.Sections[3].More)
+}
+
+func TestSection_Overflow(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, Section{Link: "https://a"}.Overflow())
+	assert.Equal(t, Text{Link("…and 14 more", "https://a")}, Section{Link: "https://a", More: 14}.Overflow())
+	assert.Equal(t, Text{Link("…and 1 more", "https://b")}, Section{Link: "https://a", MoreLink: "https://b", More: 1}.Overflow())
+	assert.Equal(t, Text{Plain("…and 2 more")}, Section{More: 2}.Overflow())
+}
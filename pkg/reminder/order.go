@@ -0,0 +1,130 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reminder
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// SectionOrder is how the sections are sorted.
+type SectionOrder string
+
+const (
+	// SectionOrderConfig keeps the order of the config, i.e. the sources.
+	SectionOrderConfig       SectionOrder = "config"
+	SectionOrderAlphabetical SectionOrder = "alphabetical"
+	SectionOrderMostItems    SectionOrder = "most-items"
+)
+
+// ItemOrder is how the items of each section and group are sorted.
+type ItemOrder string
+
+const (
+	// ItemOrderNone keeps the order of the integration, i.e. the feed.
+	ItemOrderNone   ItemOrder = ""
+	ItemOrderNewest ItemOrder = "newest"
+	ItemOrderOldest ItemOrder = "oldest"
+	// ItemOrderScore sorts by the highest score first.
+	ItemOrderScore ItemOrder = "score"
+)
+
+// ParseSectionOrder parses the section order, defaults to config.
+func ParseSectionOrder(s string) (SectionOrder, error) {
+	switch o := SectionOrder(strings.ToLower(s)); o {
+	case "":
+		return SectionOrderConfig, nil
+	case SectionOrderConfig, SectionOrderAlphabetical, SectionOrderMostItems:
+		return o, nil
+	default:
+		return "", errors.Errorf("unknown section order: '%s'", s)
+	}
+}
+
+// ParseItemOrder parses the item order, defaults to none.
+func ParseItemOrder(s string) (ItemOrder, error) {
+	switch o := ItemOrder(strings.ToLower(s)); o {
+	case ItemOrderNone, ItemOrderNewest, ItemOrderOldest, ItemOrderScore:
+		return o, nil
+	default:
+		return "", errors.Errorf("unknown item order: '%s'", s)
+	}
+}
+
+// Sort sorts the sections and the items of them. It is stable,
+// the equal ones keep the order they are generated in.
+func (r *Reminder) Sort(sections SectionOrder, items ItemOrder) {
+	for i := range r.Sections {
+		s := &r.Sections[i]
+
+		sortItems(s.Items, items)
+
+		for j := range s.Groups {
+			sortItems(s.Groups[j].Items, items)
+		}
+	}
+
+	switch sections {
+	case SectionOrderAlphabetical:
+		sort.SliceStable(r.Sections, func(i, j int) bool {
+			return strings.ToLower(r.Sections[i].Title) < strings.ToLower(r.Sections[j].Title)
+		})
+	case SectionOrderMostItems:
+		sort.SliceStable(r.Sections, func(i, j int) bool {
+			return r.Sections[i].countItems() > r.Sections[j].countItems()
+		})
+	case SectionOrderConfig:
+	}
+}
+
+// countItems returns the number of the items, including the ones of the groups.
+func (s Section) countItems() int {
+	n := len(s.Items)
+
+	for _, g := range s.Groups {
+		n += len(g.Items)
+	}
+
+	return n
+}
+
+// sortItems sorts the items, the ones without time are the last.
+func sortItems(items []Item, order ItemOrder) {
+	switch order {
+	case ItemOrderNewest, ItemOrderOldest:
+		sort.SliceStable(items, func(i, j int) bool {
+			a, b := items[i].Time, items[j].Time
+
+			if a == nil || b == nil {
+				return a != nil
+			}
+
+			if order == ItemOrderNewest {
+				return a.After(*b)
+			}
+
+			return a.Before(*b)
+		})
+	case ItemOrderScore:
+		sort.SliceStable(items, func(i, j int) bool {
+			return items[i].Score > items[j].Score
+		})
+	case ItemOrderNone:
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reminder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func titles(r *Reminder) []string {
+	result := make([]string, len(r.Sections))
+
+	for i, s := range r.Sections {
+		result[i] = s.Title
+	}
+
+	return result
+}
+
+func itemTitles(items []Item) []string {
+	result := make([]string, len(items))
+
+	for i, item := range items {
+		result[i] = item.Title
+	}
+
+	return result
+}
+
+func TestReminder_Sort(t *testing.T) {
+	t.Parallel()
+
+	t1 := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+
+	newReminder := func() *Reminder {
+		return &Reminder{
+			Sections: []Section{
+				{Title: "b", Items: []Item{{Title: "x", Time: &t1, Score: 1}}},
+				{Title: "C", Items: []Item{{Title: "none"}, {Title: "old", Time: &t1, Score: 3}, {Title: "new", Time: &t2, Score: 2}}},
+				{Title: "a", Groups: []Group{{Items: []Item{{Title: "y"}, {Title: "z"}}}}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		sections SectionOrder
+		items    ItemOrder
+		want     []string
+		wantC    []string
+	}{
+		{"config", SectionOrderConfig, ItemOrderNone, []string{"b", "C", "a"}, []string{"none", "old", "new"}},
+		{"alphabetical", SectionOrderAlphabetical, ItemOrderNewest, []string{"a", "b", "C"}, []string{"new", "old", "none"}},
+		{"most items", SectionOrderMostItems, ItemOrderOldest, []string{"C", "a", "b"}, []string{"old", "new", "none"}},
+		{"score", SectionOrderConfig, ItemOrderScore, []string{"b", "C", "a"}, []string{"old", "new", "none"}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := newReminder()
+			r.Sort(tt.sections, tt.items)
+
+			assert.Equal(t, tt.want, titles(r))
+
+			for _, s := range r.Sections {
+				if s.Title == "C" {
+					assert.Equal(t, tt.wantC, itemTitles(s.Items))
+				}
+			}
+		})
+	}
+}
+
+func TestParseOrder(t *testing.T) {
+	t.Parallel()
+
+	s, err := ParseSectionOrder("")
+	assert.NoError(t, err)
+	assert.Equal(t, SectionOrderConfig, s)
+
+	s, err = ParseSectionOrder("Most-Items")
+	assert.NoError(t, err)
+	assert.Equal(t, SectionOrderMostItems, s)
+
+	_, err = ParseSectionOrder("random")
+	assert.EqualError(t, err, "unknown section order: 'random'")
+
+	i, err := ParseItemOrder("score")
+	assert.NoError(t, err)
+	assert.Equal(t, ItemOrderScore, i)
+
+	_, err = ParseItemOrder("random")
+	assert.EqualError(t, err, "unknown item order: 'random'")
+}
@@ -0,0 +1,278 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reminder
+
+import (
+	"strings"
+	"time"
+)
+
+// Severity presents how urgent a Section is.
+type Severity int
+
+const (
+	SeverityOK Severity = iota
+	SeverityWarning
+	SeverityCritical
+)
+
+// Status presents the state of an Item, i.e. whether
+// an MR can be merged. StatusNone means it has no state.
+type Status int
+
+const (
+	StatusNone Status = iota
+	StatusOK
+	StatusFailed
+)
+
+// Marker returns the marker of the status, i.e. '✓' if
+// an MR can be merged, '✘' if it can not and '•' if none.
+func (s Status) Marker() rune {
+	switch s {
+	case StatusOK:
+		return '✓'
+	case StatusFailed:
+		return '✘'
+	case StatusNone:
+		fallthrough
+	default:
+		return '•'
+	}
+}
+
+// Reminder is the alerter-neutral message generated by the
+// integrations. Each alerter renders it into its own format.
+type Reminder struct {
+	// Source is the name of the integration that generated it.
+	Source   string
+	Sections []Section
+}
+
+// Section is a titled block of a Reminder, i.e. a GitLab project
+// or an RSS feed. Items are listed as they are, where Groups
+// are the titled item lists, i.e. the awaiting MRs.
+type Section struct {
+	Title string
+	Link  string
+	Icon  string
+
+	Summary Text
+	Groups  []Group
+	Items   []Item
+
+	Footer     string
+	FooterIcon string
+
+	Severity Severity
+
+	// Timestamp is omitted if zero.
+	Timestamp time.Time
+
+	// Channel overrides the channel of the alerter, if any.
+	Channel string
+
+	// Mentions are notified about the section, i.e. on escalation.
+	Mentions []User
+
+	// Recipient is set if the section is personal, i.e. a digest,
+	// and should be sent to the user directly instead of a channel.
+	Recipient *User
+
+	// Limit is the maximum number of the items, including the ones
+	// of the groups, 0 means no limit. It is applied by Truncate.
+	Limit int
+
+	// More is the number of the items left out by Truncate,
+	// they are summarized as "…and N more" linking to MoreLink,
+	// or to Link if it is empty.
+	More     int
+	MoreLink string
+}
+
+// Channels returns the channels of the sections in order of appearance,
+// sections without a channel are in the "" one. Direct sections are omitted.
+func (r *Reminder) Channels() []string {
+	var channels []string
+
+	seen := make(map[string]bool)
+
+	for _, s := range r.Sections {
+		if s.Recipient == nil && !seen[s.Channel] {
+			seen[s.Channel] = true
+			channels = append(channels, s.Channel)
+		}
+	}
+
+	return channels
+}
+
+// ForChannel returns a copy of the reminder with the
+// sections of the given channel only, except the direct ones.
+func (r *Reminder) ForChannel(channel string) *Reminder {
+	result := &Reminder{
+		Source: r.Source,
+	}
+
+	for _, s := range r.Sections {
+		if s.Recipient == nil && s.Channel == channel {
+			result.Sections = append(result.Sections, s)
+		}
+	}
+
+	return result
+}
+
+// Direct returns the sections to be sent to their recipients directly.
+func (r *Reminder) Direct() []Section {
+	var result []Section
+
+	for _, s := range r.Sections {
+		if s.Recipient != nil {
+			result = append(result, s)
+		}
+	}
+
+	return result
+}
+
+type Group struct {
+	Title string
+	Items []Item
+
+	// Retitle titles the group by the number of its items, if set,
+	// i.e. "2 MRs are awaiting review". Truncate calls it if it
+	// leaves out some of the items, so they agree.
+	Retitle func(n int) string `json:"-"`
+
+	// Break ends the group by an empty line even if it is the
+	// last one, i.e. the reviewed MRs in the Slack messages.
+	Break bool
+}
+
+// NewGroup returns the group of the items, titled by their number.
+func NewGroup(items []Item, title func(n int) string) Group {
+	return Group{
+		Title:   title(len(items)),
+		Items:   items,
+		Retitle: title,
+	}
+}
+
+type Item struct {
+	Status Status
+	Title  string
+	Link   string
+
+	// Details is the additional info rendered
+	// after the title, i.e. "(1 hour ago)".
+	Details Text
+
+	// Time is when the item is created or published.
+	Time *time.Time
+
+	// Score ranks the item, i.e. the points of a post
+	// or the upvotes minus the downvotes of an MR.
+	Score int
+
+	// Key identifies the item for the integration, i.e. to
+	// remember the items alerted. It is not rendered.
+	Key string `json:"-"`
+
+	Author    *User
+	Assignees []User
+	Reviewers []User
+}
+
+// OtherAssignees returns the assignees except the author,
+// who is mentioned already, i.e. the author assigned to own MR.
+func (i Item) OtherAssignees() []User {
+	return i.except(i.Assignees)
+}
+
+// OtherReviewers returns the reviewers except the author.
+func (i Item) OtherReviewers() []User {
+	return i.except(i.Reviewers)
+}
+
+func (i Item) except(users []User) []User {
+	if i.Author == nil {
+		return users
+	}
+
+	result := make([]User, 0, len(users))
+
+	for _, u := range users {
+		if u.Username != i.Author.Username {
+			result = append(result, u)
+		}
+	}
+
+	return result
+}
+
+// User is a person to be mentioned, integrations fill
+// as much as they know and the alerters resolve it.
+type User struct {
+	Username string
+	Name     string
+	Email    string
+	// Group is true if the Username is a user group
+	// or a special mention, i.e. "here".
+	Group bool
+}
+
+// Span is a piece of Text, either plain, bold or a link.
+type Span struct {
+	Text string
+	Link string
+	Bold bool
+}
+
+// Text is a formatted text, alerters render
+// each Span into their own markup.
+type Text []Span
+
+func Plain(text string) Span {
+	return Span{Text: text}
+}
+
+func Bold(text string) Span {
+	return Span{Text: text, Bold: true}
+}
+
+func Link(text, link string) Span {
+	return Span{Text: text, Link: link}
+}
+
+// Format renders the Text by formatting each Span with the given func.
+func (t Text) Format(f func(Span) string) string {
+	var b strings.Builder
+
+	for _, s := range t {
+		b.WriteString(f(s))
+	}
+
+	return b.String()
+}
+
+// String renders the Text without any markup.
+func (t Text) String() string {
+	return t.Format(func(s Span) string {
+		return s.Text
+	})
+}
@@ -0,0 +1,56 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reminder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatus_Marker(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, '✓', StatusOK.Marker())
+	assert.Equal(t, '✘', StatusFailed.Marker())
+	assert.Equal(t, '•', StatusNone.Marker())
+}
+
+func TestItem_Others(t *testing.T) {
+	t.Parallel()
+
+	foo, bar := User{Username: "foo"}, User{Username: "bar"}
+
+	tests := []struct {
+		name          string
+		item          Item
+		wantAssignees []User
+		wantReviewers []User
+	}{
+		{"it should omit the author", Item{Author: &foo, Assignees: []User{foo, bar}, Reviewers: []User{foo}}, []User{bar}, []User{}},
+		{"it should keep all without an author", Item{Assignees: []User{foo}, Reviewers: []User{bar}}, []User{foo}, []User{bar}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.wantAssignees, tt.item.OtherAssignees())
+			assert.Equal(t, tt.wantReviewers, tt.item.OtherReviewers())
+		})
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
+)
+
+var errNoJobs = errors.New("no jobs scheduled")
+
+// Scheduler runs the given jobs on their own schedules
+// until it gets stopped.
+type Scheduler struct {
+	cron *cron.Cron
+	jobs int
+}
+
+func New() *Scheduler {
+	logger := cron.PrintfLogger(log.New(os.Stderr, "", log.LstdFlags))
+
+	return &Scheduler{
+		cron: cron.New(cron.WithChain(
+			cron.Recover(logger),
+			cron.SkipIfStillRunning(logger),
+		)),
+	}
+}
+
+// Parse parses the given spec into a cron.Schedule. Spec can be
+// either a standard cron expression (i.e. "30 9 * * 1-5"), a descriptor
+// (i.e. "@hourly", "@every 15m") or a plain interval (i.e. "15m").
+func Parse(spec string) (cron.Schedule, error) {
+	spec = strings.TrimSpace(spec)
+
+	if spec == "" {
+		return nil, errors.New("empty schedule")
+	}
+
+	if d, err := time.ParseDuration(spec); err == nil {
+		if d <= 0 {
+			return nil, errors.Errorf("interval must be positive: '%s'", spec)
+		}
+
+		return cron.Every(d), nil
+	}
+
+	s, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, errors.Wrapf(err, "incorrect schedule pattern: '%s'", spec)
+	}
+
+	return s, nil
+}
+
+// Add registers the given job with the name to run on the spec.
+// Errors returned from the job are logged, they do not stop the scheduler.
+func (s *Scheduler) Add(name, spec string, job func() error) error {
+	schedule, err := Parse(spec)
+	if err != nil {
+		return errors.Wrapf(err, "unable to schedule job: '%s'", name)
+	}
+
+	s.cron.Schedule(schedule, cron.FuncJob(func() {
+		log.Printf("running scheduled job: %s\n", name)
+
+		if err := job(); err != nil {
+			log.Printf("scheduled job '%s' failed: %v\n", name, err)
+		}
+	}))
+
+	s.jobs++
+
+	log.Printf("job '%s' scheduled: %s\n", name, spec)
+
+	return nil
+}
+
+// Run starts the scheduler and blocks until the ctx is done.
+// It waits for the running jobs to complete before return.
+func (s *Scheduler) Run(ctx context.Context) error {
+	if s.jobs == 0 {
+		return errNoJobs
+	}
+
+	s.cron.Start()
+
+	<-ctx.Done()
+
+	log.Println("shutting down the scheduler, waiting for the running jobs")
+
+	<-s.cron.Stop().Done()
+
+	return nil
+}
@@ -0,0 +1,109 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	from := time.Date(2021, time.April, 5, 8, 0, 0, 0, time.UTC) // Monday
+
+	tests := []struct {
+		name    string
+		spec    string
+		want    time.Time
+		wantErr bool
+	}{
+		{
+			"it should parse cron expression",
+			"30 9 * * 1-5",
+			time.Date(2021, time.April, 5, 9, 30, 0, 0, time.UTC),
+			false,
+		},
+		{
+			"it should parse descriptor",
+			"@every 15m",
+			time.Date(2021, time.April, 5, 8, 15, 0, 0, time.UTC),
+			false,
+		},
+		{
+			"it should parse plain interval",
+			"15m",
+			time.Date(2021, time.April, 5, 8, 15, 0, 0, time.UTC),
+			false,
+		},
+		{
+			"it should not parse empty spec",
+			"",
+			time.Time{},
+			true,
+		},
+		{
+			"it should not parse negative interval",
+			"-1h",
+			time.Time{},
+			true,
+		},
+		{
+			"it should not parse bad cron expression",
+			"30 9 * *",
+			time.Time{},
+			true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Parse(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			assert.Equal(t, tt.want, got.Next(from))
+		})
+	}
+}
+
+func TestScheduler_Run(t *testing.T) {
+	t.Parallel()
+
+	assert.Error(t, New().Run(context.Background()))
+
+	s := New()
+
+	assert.NoError(t, s.Add("job", "@every 1h", func() error { return nil }))
+	assert.Error(t, s.Add("bad", "foo", func() error { return nil }))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.NoError(t, s.Run(ctx))
+}
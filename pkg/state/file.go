@@ -0,0 +1,99 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// FileStore keeps the entries in memory and
+// persists them as a JSON file on Save.
+type FileStore struct {
+	*MemoryStore
+
+	path string
+}
+
+// NewFileStore loads the entries from the given
+// path, a missing file means an empty state.
+func NewFileStore(path string, retention time.Duration) (*FileStore, error) {
+	f := &FileStore{
+		MemoryStore: NewMemoryStore(retention),
+		path:        path,
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return f, nil
+	}
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read state file: '%s'", path)
+	}
+
+	if err := json.Unmarshal(b, &f.entries); err != nil {
+		return nil, errors.Wrapf(err, "unable to unmarshal state file: '%s'", path)
+	}
+
+	if f.entries == nil {
+		f.entries = make(map[string]map[string]Entry)
+	}
+
+	return f, nil
+}
+
+func (f *FileStore) Save() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.prune(time.Now().Add(-f.retention))
+
+	b, err := json.MarshalIndent(f.entries, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal state")
+	}
+
+	// Write to a temp file first, so we do not
+	// end up with a corrupted state on a crash.
+	tmp, err := ioutil.TempFile(filepath.Dir(f.path), filepath.Base(f.path)+".*")
+	if err != nil {
+		return errors.Wrapf(err, "unable to create temp state file for: '%s'", f.path)
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return errors.Wrapf(err, "unable to write state file: '%s'", tmp.Name())
+	}
+
+	if err := tmp.Close(); err != nil {
+		return errors.Wrapf(err, "unable to close state file: '%s'", tmp.Name())
+	}
+
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return errors.Wrapf(err, "unable to save state file: '%s'", f.path)
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps the entries in memory, it is
+// only useful for the long-running daemon mode.
+type MemoryStore struct {
+	mu        sync.RWMutex
+	entries   map[string]map[string]Entry
+	retention time.Duration
+}
+
+func NewMemoryStore(retention time.Duration) *MemoryStore {
+	return &MemoryStore{
+		entries:   make(map[string]map[string]Entry),
+		retention: retention,
+	}
+}
+
+func (m *MemoryStore) Get(namespace, key string) (Entry, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	e, ok := m.entries[namespace][key]
+
+	return e, ok
+}
+
+func (m *MemoryStore) Put(namespace, key string, entry Entry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.entries[namespace]; !ok {
+		m.entries[namespace] = make(map[string]Entry)
+	}
+
+	m.entries[namespace][key] = entry
+}
+
+func (m *MemoryStore) Save() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.prune(time.Now().Add(-m.retention))
+
+	return nil
+}
+
+// prune drops the entries older than the given time,
+// caller must hold the lock.
+func (m *MemoryStore) prune(before time.Time) {
+	for ns, entries := range m.entries {
+		for k, e := range entries {
+			if e.Time.Before(before) {
+				delete(entries, k)
+			}
+		}
+
+		if len(entries) == 0 {
+			delete(m.entries, ns)
+		}
+	}
+}
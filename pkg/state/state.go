@@ -0,0 +1,87 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Dentrax/remind-us/pkg/config"
+	"github.com/pkg/errors"
+)
+
+const (
+	TypeFile   = "file"
+	TypeMemory = "memory"
+
+	defaultPath      = "./remind-us.state.json"
+	defaultRetention = 7 * 24 * time.Hour
+)
+
+// Entry is a single record in a namespace.
+type Entry struct {
+	Value string    `json:"value,omitempty"`
+	Time  time.Time `json:"time"`
+}
+
+// IStore persists the entries across the runs. Entries are grouped
+// by namespaces, i.e. an RSS source URL.
+type IStore interface {
+	Get(namespace, key string) (Entry, bool)
+	Put(namespace, key string, entry Entry)
+	// Save persists the entries, the ones
+	// older than the retention get dropped.
+	Save() error
+}
+
+// New creates the store for the given config. Returns
+// nil if the config is nil since the state is optional.
+func New(c *config.StateConfig) (IStore, error) {
+	if c == nil {
+		return nil, nil
+	}
+
+	retention := defaultRetention
+
+	if c.Retention != "" {
+		r, err := time.ParseDuration(c.Retention)
+		if err != nil {
+			return nil, errors.Wrapf(err, "incorrect 'retention' pattern: '%s'", c.Retention)
+		}
+
+		retention = r
+	}
+
+	switch strings.ToLower(c.Type) {
+	case "", TypeFile:
+		path := c.Path
+		if path == "" {
+			path = defaultPath
+		}
+
+		f, err := NewFileStore(path, retention)
+		if err != nil {
+			return nil, err
+		}
+
+		return f, nil
+	case TypeMemory:
+		return NewMemoryStore(retention), nil
+	default:
+		return nil, errors.Errorf("unknown state type: '%s'", c.Type)
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright © 2021 Furkan Türkal
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Dentrax/remind-us/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		config  *config.StateConfig
+		wantNil bool
+		wantErr bool
+	}{
+		{
+			"it should return nil if no config",
+			nil,
+			true,
+			false,
+		},
+		{
+			"it should create memory store",
+			&config.StateConfig{Type: "memory", Retention: "1h"},
+			false,
+			false,
+		},
+		{
+			"it should not create if bad retention",
+			&config.StateConfig{Type: "memory", Retention: "foo"},
+			true,
+			true,
+		},
+		{
+			"it should not create if unknown type",
+			&config.StateConfig{Type: "foo"},
+			true,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := New(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			assert.Equal(t, tt.wantNil, got == nil)
+		})
+	}
+}
+
+func TestFileStore_Save(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	s, err := NewFileStore(path, time.Hour)
+	assert.NoError(t, err)
+
+	s.Put("https://foo.bar/rss", "new", Entry{Time: time.Now()})
+	s.Put("https://foo.bar/rss", "old", Entry{Time: time.Now().Add(-2 * time.Hour)})
+	s.Put("https://bar.baz/rss", "old", Entry{Time: time.Now().Add(-2 * time.Hour)})
+
+	assert.NoError(t, s.Save())
+
+	loaded, err := NewFileStore(path, time.Hour)
+	assert.NoError(t, err)
+
+	_, ok := loaded.Get("https://foo.bar/rss", "new")
+	assert.True(t, ok)
+
+	_, ok = loaded.Get("https://foo.bar/rss", "old")
+	assert.False(t, ok)
+
+	_, ok = loaded.Get("https://bar.baz/rss", "old")
+	assert.False(t, ok)
+}